@@ -1,27 +1,41 @@
 package dcel
 
-import "github.com/gonum/graph"
+import "gonum.org/v1/gonum/graph"
 
 var (
-	_ Node     = ((*BaseNode)(nil))
-	_ Halfedge = ((*BaseHalfedge)(nil))
-	_ Edge     = ((*BaseEdge)(nil))
-	_ Face     = ((*BaseFace)(nil))
+	_ Node         = ((*BaseNode)(nil))
+	_ PointNode    = ((*BasePointNode)(nil))
+	_ Halfedge     = ((*BaseHalfedge)(nil))
+	_ Edge         = ((*BaseEdge)(nil))
+	_ WeightedEdge = ((*BaseEdge)(nil))
+	_ Face         = ((*BaseFace)(nil))
 )
 
 type BaseNode struct {
-	id int
+	id int64
 	h  Halfedge
 }
 
-func NewBaseNode(id int) *BaseNode {
+func NewBaseNode(id int64) *BaseNode {
 	return &BaseNode{id: id}
 }
 
-func (n *BaseNode) ID() int                { return n.id }
+func (n *BaseNode) ID() int64              { return n.id }
 func (n *BaseNode) Halfedge() Halfedge     { return n.h }
 func (n *BaseNode) SetHalfedge(h Halfedge) { n.h = h }
 
+type BasePointNode struct {
+	BaseNode
+	p Point
+}
+
+func NewBasePointNode(id int64) *BasePointNode {
+	return &BasePointNode{BaseNode: BaseNode{id: id}}
+}
+
+func (n *BasePointNode) Point() Point     { return n.p }
+func (n *BasePointNode) SetPoint(p Point) { n.p = p }
+
 type BaseHalfedge struct {
 	from       Node
 	twin       Halfedge
@@ -48,31 +62,66 @@ func (h *BaseHalfedge) Face() Face          { return h.face }
 func (h *BaseHalfedge) SetFace(f Face)      { h.face = f }
 
 type BaseEdge struct {
-	id     int
+	id     int64
 	h1, h2 Halfedge
+	// w holds the weight shared by the edge and its reversals. A nil w
+	// means unit weight.
+	w *float64
 }
 
-func NewBaseEdge(id int) *BaseEdge {
+// NewBaseEdge returns a new edge with the given id and with unit weight.
+func NewBaseEdge(id int64) *BaseEdge {
 	return &BaseEdge{id: id}
 }
 
-func (e *BaseEdge) ID() int                         { return e.id }
+func (e *BaseEdge) ID() int64                       { return e.id }
 func (e *BaseEdge) From() graph.Node                { return e.h1.From() }
 func (e *BaseEdge) To() graph.Node                  { return e.h2.From() }
-func (e *BaseEdge) Weight() float64                 { return 1 }
 func (e *BaseEdge) Halfedges() (Halfedge, Halfedge) { return e.h1, e.h2 }
 func (e *BaseEdge) SetHalfedges(h1, h2 Halfedge)    { e.h1, e.h2 = h1, h2 }
 
+// Weight returns the weight of e.
+func (e *BaseEdge) Weight() float64 {
+	if e.w == nil {
+		return 1
+	}
+	return *e.w
+}
+
+// SetWeight sets the weight of e and of its reversals.
+func (e *BaseEdge) SetWeight(w float64) { *e.weight() = w }
+
+// weight returns the storage of the weight of e, allocating it if needed.
+func (e *BaseEdge) weight() *float64 {
+	if e.w == nil {
+		e.w = new(float64)
+		*e.w = 1
+	}
+	return e.w
+}
+
+// ReversedEdge returns a copy of e with the order of halfedges, and thus the
+// From and To nodes, swapped. The copy shares the weight with e.
+func (e *BaseEdge) ReversedEdge() graph.Edge { return e.reversed() }
+
+// ReversedLine returns a copy of e with the order of halfedges, and thus the
+// From and To nodes, swapped. The copy shares the weight with e.
+func (e *BaseEdge) ReversedLine() graph.Line { return e.reversed() }
+
+func (e *BaseEdge) reversed() *BaseEdge {
+	return &BaseEdge{id: e.id, h1: e.h2, h2: e.h1, w: e.weight()}
+}
+
 type BaseFace struct {
-	id int
+	id int64
 	h  Halfedge
 }
 
-func NewBaseFace(id int) *BaseFace {
+func NewBaseFace(id int64) *BaseFace {
 	return &BaseFace{id: id}
 }
 
-func (f *BaseFace) ID() int                { return f.id }
+func (f *BaseFace) ID() int64              { return f.id }
 func (f *BaseFace) Halfedge() Halfedge     { return f.h }
 func (f *BaseFace) SetHalfedge(h Halfedge) { f.h = h }
 
@@ -80,7 +129,13 @@ func (f *BaseFace) SetHalfedge(h Halfedge) { f.h = h }
 // structure.
 type Base struct{}
 
-func (Base) NewNode(id int) Node   { return NewBaseNode(id) }
+func (Base) NewNode(id int64) Node { return NewBaseNode(id) }
 func (Base) NewHalfedge() Halfedge { return NewBaseHalfedge() }
-func (Base) NewEdge(id int) Edge   { return NewBaseEdge(id) }
-func (Base) NewFace(id int) Face   { return NewBaseFace(id) }
+func (Base) NewEdge(id int64) Edge { return NewBaseEdge(id) }
+func (Base) NewFace(id int64) Face { return NewBaseFace(id) }
+
+// PointBase implements Items interface for allocating base elements of DCEL
+// data structure whose nodes carry a position.
+type PointBase struct{ Base }
+
+func (PointBase) NewNode(id int64) Node { return NewBasePointNode(id) }
@@ -0,0 +1,182 @@
+package dcel
+
+import "fmt"
+
+// InsertConstraint inserts a constrained edge between the nodes u and v of the
+// triangulation. The triangles crossed by the segment between u and v are
+// removed and the two polygons on either side of the segment are
+// retriangulated so that the triangulation is constrained Delaunay. If the
+// segment passes through other nodes, it is split at them into several
+// constrained edges.
+//
+// Constrained edges are never removed by subsequent insertions. A point
+// inserted on a constrained edge splits the edge into two constrained edges.
+//
+// If u or v is not in the triangulation, if u and v are the same node or if the
+// segment crosses another constrained edge, an error is returned and the
+// triangulation is not modified.
+func (t *Triangulation) InsertConstraint(u, v Node) error {
+	uid, vid := u.ID(), v.ID()
+	u, v = t.g.nodes[uid], t.g.nodes[vid]
+	switch {
+	case u == nil:
+		return fmt.Errorf("dcel: node %d not in triangulation", uid)
+	case v == nil:
+		return fmt.Errorf("dcel: node %d not in triangulation", vid)
+	case uid == vid:
+		return fmt.Errorf("dcel: cannot constrain node %d to itself", uid)
+	}
+
+	// Check that the segment does not cross any constrained edges before
+	// the triangulation is modified.
+	for w := u; w != v; {
+		next, crossed := t.trace(w, v)
+		for _, h := range crossed {
+			if t.isConstrained(h) {
+				return fmt.Errorf("dcel: constraint from %d to %d crosses constrained edge from %d to %d",
+					uid, vid, h.From().ID(), h.Twin().From().ID())
+			}
+		}
+		w = next
+	}
+
+	for u != v {
+		w, crossed := t.trace(u, v)
+		if len(crossed) > 0 {
+			t.retriangulate(u, w, crossed)
+		}
+		t.constrained[edgeKey(u.ID(), w.ID())] = true
+		u = w
+	}
+	return nil
+}
+
+// IsConstrained returns whether e is a constrained edge of the triangulation.
+func (t *Triangulation) IsConstrained(e Edge) bool {
+	h, _ := e.Halfedges()
+	return t.isConstrained(h)
+}
+
+func (t *Triangulation) isConstrained(h Halfedge) bool {
+	return t.constrained[edgeKey(h.From().ID(), h.Twin().From().ID())]
+}
+
+// trace follows the segment from u towards v and returns the first node w on
+// the segment and the halfedges of the edges that the segment crosses between u
+// and w. Each halfedge goes from the node on the right of the segment to the
+// node on its left and its face is the triangle in which the segment enters the
+// edge.
+func (t *Triangulation) trace(u, v Node) (w Node, crossed []Halfedge) {
+	pu, pv := point(u), point(v)
+
+	// Find the edge from u in the direction of v or the triangle around u
+	// through which the segment leaves u.
+	var e Halfedge
+	for _, h := range t.g.HalfedgesFrom(u.ID()) {
+		a := h.Twin().From()
+		if a == v {
+			return v, nil
+		}
+		pa := point(a)
+		if orient(pu, pv, pa) == 0 && pa.Sub(pu).Dot(pv.Sub(pu)) > 0 {
+			return a, nil
+		}
+		if h.Face() == nil {
+			continue
+		}
+		b := h.Prev().From()
+		if orient(pu, pa, pv) > 0 && orient(pu, point(b), pv) < 0 {
+			e = h.Next()
+		}
+	}
+	if e == nil {
+		// Both nodes lie in the convex hull of the triangulation, so
+		// the segment must leave u through one of its triangles.
+		panic("dcel: constraint tracing failed")
+	}
+
+	// Walk across the triangles crossed by the segment.
+	for {
+		crossed = append(crossed, e)
+		tw := e.Twin()
+		c := tw.Prev().From()
+		if c == v {
+			return v, crossed
+		}
+		switch o := orient(pu, pv, point(c)); {
+		case o == 0:
+			return c, crossed
+		case o > 0:
+			e = tw.Next()
+		default:
+			e = tw.Prev()
+		}
+	}
+}
+
+// retriangulate replaces the triangles crossed by the segment from u to w with
+// triangles that contain the edge between u and w. crossed are the halfedges
+// returned by trace.
+func (t *Triangulation) retriangulate(u, w Node, crossed []Halfedge) {
+	// Collect the nodes of the polygons on the left and on the right of the
+	// segment in the order from u to w.
+	left := []Node{crossed[0].Twin().From()}
+	right := []Node{crossed[0].From()}
+	for _, h := range crossed[1:] {
+		if l := h.Twin().From(); l != left[len(left)-1] {
+			left = append(left, l)
+		}
+		if r := h.From(); r != right[len(right)-1] {
+			right = append(right, r)
+		}
+	}
+
+	faces := []Face{crossed[0].Face()}
+	edges := make([][2]int64, len(crossed))
+	for i, h := range crossed {
+		faces = append(faces, h.Twin().Face())
+		edges[i] = [2]int64{h.From().ID(), h.Twin().From().ID()}
+	}
+	for _, f := range faces {
+		t.g.RemoveFace(f)
+	}
+	for _, e := range edges {
+		t.g.RemoveEdge(e[0], e[1])
+	}
+
+	for i, j := 0, len(right)-1; i < j; i, j = i+1, j-1 {
+		right[i], right[j] = right[j], right[i]
+	}
+	t.fillPolygon(u, w, left)
+	t.fillPolygon(w, u, right)
+}
+
+// fillPolygon triangulates the polygon formed by the edge from a to b and the
+// nodes in chain that lie on the left of the edge and are ordered from a to b.
+// The triangles are constrained Delaunay with respect to the polygon.
+func (t *Triangulation) fillPolygon(a, b Node, chain []Node) {
+	if len(chain) == 0 {
+		return
+	}
+	// Find the node whose circle through a and b contains no other node.
+	pa, pb := point(a), point(b)
+	k := 0
+	for i := 1; i < len(chain); i++ {
+		if inCircle(pa, pb, point(chain[k]), point(chain[i])) > 0 {
+			k = i
+		}
+	}
+	c := chain[k]
+	t.addTriangle(a, b, c)
+	t.fillPolygon(a, c, chain[:k])
+	t.fillPolygon(c, b, chain[k+1:])
+}
+
+// edgeKey returns a key that identifies the edge between nodes with IDs uid and
+// vid regardless of its direction.
+func edgeKey(uid, vid int64) [2]int64 {
+	if uid > vid {
+		uid, vid = vid, uid
+	}
+	return [2]int64{uid, vid}
+}
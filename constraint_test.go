@@ -0,0 +1,108 @@
+package dcel
+
+import (
+	"math/rand/v2"
+	"testing"
+)
+
+func TestInsertConstraint(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(1, 2))
+	const n = 300
+	points := make([]Point, n)
+	for i := range points {
+		points[i] = Point{X: rnd.Float64(), Y: rnd.Float64()}
+	}
+	tri, err := NewDelaunay(nil, points)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := tri.Graph()
+	var constraints [][2]int64
+	for len(constraints) < 20 {
+		u, v := g.nodes[rnd.Int64N(n)], g.nodes[rnd.Int64N(n)]
+		if u == v {
+			continue
+		}
+		faces := len(g.Faces())
+		err := tri.InsertConstraint(u, v)
+		if err != nil {
+			if len(g.Faces()) != faces {
+				t.Fatal("dcel: triangulation modified by failed constraint insertion")
+			}
+			continue
+		}
+		constraints = append(constraints, [2]int64{u.ID(), v.ID()})
+		checkDelaunay(t, tri)
+	}
+	for _, c := range constraints {
+		e := g.EdgeBetween(c[0], c[1])
+		if e == nil {
+			t.Fatalf("dcel: missing constrained edge between %d and %d", c[0], c[1])
+		}
+		if !tri.IsConstrained(e.(Edge)) {
+			t.Errorf("dcel: edge between %d and %d not constrained", c[0], c[1])
+		}
+	}
+
+	// Insert more points, constrained edges must be preserved.
+	for i := 0; i < 300; i++ {
+		if _, err := tri.Insert(Point{X: rnd.Float64(), Y: rnd.Float64()}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	checkDelaunay(t, tri)
+	for _, c := range constraints {
+		if !g.HasEdgeBetween(c[0], c[1]) {
+			t.Errorf("dcel: constrained edge between %d and %d removed", c[0], c[1])
+		}
+	}
+}
+
+func TestInsertConstraintGrid(t *testing.T) {
+	var points []Point
+	for i := 0; i < 10; i++ {
+		for j := 0; j < 10; j++ {
+			points = append(points, Point{X: float64(i), Y: float64(j)})
+		}
+	}
+	tri, err := NewDelaunay(nil, points)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := tri.Graph()
+
+	// The diagonal passes through the nodes (i, i) and it is split at them.
+	if err := tri.InsertConstraint(g.nodes[0], g.nodes[99]); err != nil {
+		t.Fatal(err)
+	}
+	checkDelaunay(t, tri)
+	for i := int64(0); i < 9; i++ {
+		e := g.EdgeBetween(11*i, 11*(i+1))
+		if e == nil || !tri.IsConstrained(e.(Edge)) {
+			t.Errorf("dcel: missing constrained edge between %d and %d", 11*i, 11*(i+1))
+		}
+	}
+
+	if err := tri.InsertConstraint(g.nodes[9], g.nodes[90]); err == nil {
+		t.Error("dcel: expected error for crossing constraints")
+	}
+	if err := tri.InsertConstraint(g.nodes[9], g.nodes[9]); err == nil {
+		t.Error("dcel: expected error for degenerate constraint")
+	}
+
+	// A point on a constrained edge splits it.
+	u, err := tri.Insert(Point{X: 4.5, Y: 4.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkDelaunay(t, tri)
+	if g.HasEdgeBetween(44, 55) {
+		t.Error("dcel: split constrained edge not removed")
+	}
+	for _, v := range []int64{44, 55} {
+		e := g.EdgeBetween(u.ID(), v)
+		if e == nil || !tri.IsConstrained(e.(Edge)) {
+			t.Errorf("dcel: missing constrained edge between %d and %d", u.ID(), v)
+		}
+	}
+}
@@ -2,89 +2,115 @@ package dcel
 
 import (
 	"fmt"
+	"math"
+	"sort"
 
-	"github.com/gonum/graph"
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/graph/iterator"
 )
 
 var (
 	dcelGraph *Graph
-	_         graph.Undirected = dcelGraph
+	_         graph.Undirected         = dcelGraph
+	_         graph.WeightedUndirected = dcelGraph
 )
 
 // Graph implements the doubly-connected edge list data structure.
 type Graph struct {
-	items Items
+	items  Items
+	weight WeightFunc
 
-	nodes map[int]Node
-	edges map[int]Edge
-	faces map[int]Face
+	nodes map[int64]Node
+	edges map[int64]Edge
+	faces map[int64]Face
 
-	nextNodeID int
-	nextEdgeID int
-	nextFaceID int
+	nextNodeID int64
+	nextEdgeID int64
+	nextFaceID int64
 
-	freeNodes map[int]struct{}
-	freeEdges map[int]struct{}
-	freeFaces map[int]struct{}
+	freeNodes map[int64]struct{}
+	freeEdges map[int64]struct{}
+	freeFaces map[int64]struct{}
+
+	props     properties
+	observers []Observer
 }
 
+// WeightFunc returns the weight of an edge.
+type WeightFunc func(Edge) float64
+
 // New returns a new Graph. If items is nil, Base will be used.
 func New(items Items) *Graph {
+	return NewWeighted(items, nil)
+}
+
+// NewWeighted returns a new Graph whose edge weights are given by the weight
+// function. If items is nil, Base will be used. If weight is nil, the weights
+// are given by the Weight method of the edges.
+func NewWeighted(items Items, weight WeightFunc) *Graph {
 	if items == nil {
 		items = Base{}
 	}
 	return &Graph{
-		items: items,
+		items:  items,
+		weight: weight,
 
-		nodes: make(map[int]Node),
-		edges: make(map[int]Edge),
-		faces: make(map[int]Face),
+		nodes: make(map[int64]Node),
+		edges: make(map[int64]Edge),
+		faces: make(map[int64]Face),
 
-		freeNodes: make(map[int]struct{}),
-		freeEdges: make(map[int]struct{}),
-		freeFaces: make(map[int]struct{}),
+		freeNodes: make(map[int64]struct{}),
+		freeEdges: make(map[int64]struct{}),
+		freeFaces: make(map[int64]struct{}),
 	}
 }
 
 // Node returns the node with the given id or nil if it does not exist within
-// the graph.
-func (g *Graph) Node(id int) Node { return g.nodes[id] }
+// the graph. The returned node, if not nil, is a Node.
+func (g *Graph) Node(id int64) graph.Node {
+	u, ok := g.nodes[id]
+	if !ok {
+		return nil
+	}
+	return u
+}
 
 // Face returns the face with the given id or nil if it does not exist within
 // the graph.
-func (g *Graph) Face(id int) Face { return g.faces[id] }
+func (g *Graph) Face(id int64) Face { return g.faces[id] }
 
 // Edge returns the edge with the given id or nil if it does not exist within
 // the graph.
-// func (g *Graph) Edge(id int) Edge { return g.edges[id] }
-
-// Has returns whether a node with the id given by x.ID() exists within the graph.
-func (g *Graph) Has(x graph.Node) bool {
-	return g.has(x.ID())
-}
+// func (g *Graph) Edge(id int64) Edge { return g.edges[id] }
 
 // has returns whether a node with the given id exists within the graph.
-func (g *Graph) has(id int) bool {
+func (g *Graph) has(id int64) bool {
 	_, exists := g.nodes[id]
 	return exists
 }
 
 // Nodes returns all the nodes in the graph.
-func (g *Graph) Nodes() []graph.Node {
-	var nodes []graph.Node
+func (g *Graph) Nodes() graph.Nodes {
+	if len(g.nodes) == 0 {
+		return graph.Empty
+	}
+	nodes := make([]graph.Node, 0, len(g.nodes))
 	for _, u := range g.nodes {
 		nodes = append(nodes, u)
 	}
-	return nodes
+	return iterator.NewOrderedNodes(nodes)
 }
 
 // Edges returns all the edges in the graph.
-func (g *Graph) Edges() []graph.Edge {
-	var edges []graph.Edge
+func (g *Graph) Edges() graph.Edges {
+	if len(g.edges) == 0 {
+		return graph.Empty
+	}
+	edges := make([]graph.Edge, 0, len(g.edges))
 	for _, e := range g.edges {
 		edges = append(edges, e)
 	}
-	return edges
+	return iterator.NewOrderedEdges(edges)
 }
 
 // Faces returns all the faces in the graph.
@@ -96,15 +122,15 @@ func (g *Graph) Faces() []Face {
 	return faces
 }
 
-// From returns all neighbors of the node x.
-func (g *Graph) From(x graph.Node) []graph.Node {
-	u := g.Node(x.ID())
+// From returns all neighbors of the node with the given id.
+func (g *Graph) From(id int64) graph.Nodes {
+	u := g.nodes[id]
 	if u == nil {
-		return nil
+		return graph.Empty
 	}
 	if u.Halfedge() == nil {
 		// Node n is isolated, so there are no neighbors.
-		return nil
+		return graph.Empty
 	}
 	var (
 		from  []graph.Node
@@ -117,34 +143,133 @@ func (g *Graph) From(x graph.Node) []graph.Node {
 			break
 		}
 	}
-	return from
+	return iterator.NewOrderedNodes(from)
 }
 
-// HasEdge returns whether an edge exists between nodes x and y.
-func (g *Graph) HasEdge(x, y graph.Node) bool {
-	return g.Halfedge(x, y) != nil
+// HasEdgeBetween returns whether an edge exists between nodes with IDs xid and
+// yid.
+func (g *Graph) HasEdgeBetween(xid, yid int64) bool {
+	return g.Halfedge(xid, yid) != nil
 }
 
-// Edge returns the edge between x and y or nil if the nodes are not connected.
-func (g *Graph) Edge(x, y graph.Node) graph.Edge {
-	return g.EdgeBetween(x, y)
+// Edge returns the edge between nodes with IDs uid and vid or nil if the nodes
+// are not connected. The returned edge, if not nil, is the Edge stored in the
+// graph.
+func (g *Graph) Edge(uid, vid int64) graph.Edge {
+	return g.EdgeBetween(uid, vid)
 }
 
-// EdgeBetween returns the edge between x and y or nil if the nodes are not
-// connected.
-func (g *Graph) EdgeBetween(x, y graph.Node) graph.Edge {
-	he := g.Halfedge(x, y)
+// EdgeBetween returns the edge between nodes with IDs xid and yid or nil if
+// the nodes are not connected. The returned edge, if not nil, is the Edge
+// stored in the graph.
+func (g *Graph) EdgeBetween(xid, yid int64) graph.Edge {
+	he := g.Halfedge(xid, yid)
 	if he == nil {
 		return nil
 	}
 	return he.Edge()
 }
 
-// Halfedge returns the halfedge from x to y, or nil if the nodes are not
-// connected by an edge or at least one is isolated.
-func (g *Graph) Halfedge(x, y graph.Node) Halfedge {
-	u := g.Node(x.ID())
-	v := g.Node(y.ID())
+// WeightedEdge returns the weighted edge between nodes with IDs uid and vid or
+// nil if the nodes are not connected. The weight of the returned edge is given
+// by the weight function of the graph. If the graph has a weight function, the
+// returned edge is a read-only Edge that wraps the edge stored in the graph.
+func (g *Graph) WeightedEdge(uid, vid int64) graph.WeightedEdge {
+	return g.WeightedEdgeBetween(uid, vid)
+}
+
+// WeightedEdgeBetween returns the weighted edge between nodes with IDs xid and
+// yid or nil if the nodes are not connected. The weight of the returned edge is
+// given by the weight function of the graph. If the graph has a weight
+// function, the returned edge is a read-only Edge that wraps the edge stored in
+// the graph.
+func (g *Graph) WeightedEdgeBetween(xid, yid int64) graph.WeightedEdge {
+	he := g.Halfedge(xid, yid)
+	if he == nil {
+		return nil
+	}
+	return g.weighted(he.Edge())
+}
+
+// Weight returns the weight of the edge between nodes with IDs xid and yid. If
+// xid == yid, Weight returns 0 and true. If the nodes are not connected,
+// Weight returns +Inf and false.
+func (g *Graph) Weight(xid, yid int64) (w float64, ok bool) {
+	if xid == yid {
+		return 0, true
+	}
+	he := g.Halfedge(xid, yid)
+	if he == nil {
+		return math.Inf(1), false
+	}
+	return g.edgeWeight(he.Edge()), true
+}
+
+// edgeWeight returns the weight of e given by the weight function of the
+// graph.
+func (g *Graph) edgeWeight(e Edge) float64 {
+	if g.weight == nil {
+		return e.Weight()
+	}
+	return g.weight(e)
+}
+
+// weighted returns e with the weight given by the weight function of the
+// graph. If the graph has no weight function, e is returned unchanged.
+func (g *Graph) weighted(e Edge) Edge {
+	if g.weight == nil {
+		return e
+	}
+	return weightedEdge{Edge: e, w: g.weight(e)}
+}
+
+// weightedEdge is an Edge with a weight given by the weight function of a
+// graph.
+type weightedEdge struct {
+	Edge
+	w float64
+}
+
+func (e weightedEdge) Weight() float64 { return e.w }
+
+// ReversedEdge returns the reversal of the wrapped edge with the same weight.
+func (e weightedEdge) ReversedEdge() graph.Edge { return e.reversed() }
+
+// ReversedLine returns the reversal of the wrapped edge with the same weight.
+func (e weightedEdge) ReversedLine() graph.Line { return e.reversed() }
+
+// reversed returns the reversal of e with the same weight. If the reversal of
+// the wrapped edge is not an Edge, the wrapped edge is reversed by
+// reversedEdge.
+func (e weightedEdge) reversed() weightedEdge {
+	if r, ok := e.Edge.ReversedEdge().(Edge); ok {
+		return weightedEdge{Edge: r, w: e.w}
+	}
+	return weightedEdge{Edge: reversedEdge{e.Edge}, w: e.w}
+}
+
+// reversedEdge is an Edge with the order of halfedges, and thus the From and To
+// nodes, of the wrapped edge swapped.
+type reversedEdge struct {
+	Edge
+}
+
+func (e reversedEdge) From() graph.Node             { return e.Edge.To() }
+func (e reversedEdge) To() graph.Node               { return e.Edge.From() }
+func (e reversedEdge) ReversedEdge() graph.Edge     { return e.Edge }
+func (e reversedEdge) ReversedLine() graph.Line     { return e.Edge }
+func (e reversedEdge) SetHalfedges(h1, h2 Halfedge) { e.Edge.SetHalfedges(h2, h1) }
+func (e reversedEdge) Halfedges() (Halfedge, Halfedge) {
+	h1, h2 := e.Edge.Halfedges()
+	return h2, h1
+}
+
+// Halfedge returns the halfedge from the node with ID uid to the node with ID
+// vid, or nil if the nodes are not connected by an edge or at least one is
+// isolated.
+func (g *Graph) Halfedge(uid, vid int64) Halfedge {
+	u := g.nodes[uid]
+	v := g.nodes[vid]
 	if u == nil || v == nil {
 		// One of the nodes does not belong to the graph.
 		return nil
@@ -168,8 +293,8 @@ func (g *Graph) Halfedge(x, y graph.Node) Halfedge {
 }
 
 // NewNodeID returns a new node id unique within the graph.
-func (g *Graph) NewNodeID() int {
-	if g.nextNodeID != maxInt {
+func (g *Graph) NewNodeID() int64 {
+	if g.nextNodeID != maxID {
 		id := g.nextNodeID
 		g.nextNodeID++
 		return id
@@ -179,12 +304,12 @@ func (g *Graph) NewNodeID() int {
 	for id := range g.freeNodes {
 		return id
 	}
-	if len(g.nodes) == maxInt {
+	if int64(len(g.nodes)) == maxID {
 		panic("dcel: graph too large")
 	}
 	// Resort to checking all positive integers to see if there is at least one
 	// unused.
-	for id := 0; id < maxInt; id++ {
+	for id := int64(0); id < maxID; id++ {
 		if _, exists := g.nodes[id]; !exists {
 			return id
 		}
@@ -194,7 +319,7 @@ func (g *Graph) NewNodeID() int {
 
 // AddNode adds a new, isolated node with the given id to the graph and returns it.
 // AddNode panics if a node with same id already exists in the graph.
-func (g *Graph) AddNode(id int) Node {
+func (g *Graph) AddNode(id int64) Node {
 	if g.has(id) {
 		panic(fmt.Sprintf("dcel: node ID collision: %d", id))
 	}
@@ -204,31 +329,43 @@ func (g *Graph) AddNode(id int) Node {
 
 	g.nodes[id] = u
 	delete(g.freeNodes, id)
-	g.nextNodeID = max(g.nextNodeID, id)
+	g.nextNodeID = nextID(g.nextNodeID, id)
 
+	for _, o := range g.observers {
+		o.NodeAdded(u)
+	}
 	return u
 }
 
-// RemoveNode removes the node with ID given by x.ID() from the graph as well
-// as any edges attached to it.
-func (g *Graph) RemoveNode(x graph.Node) {
-	id := x.ID()
+// RemoveNode removes the node with the given id from the graph as well as any
+// edges attached to it.
+func (g *Graph) RemoveNode(id int64) {
 	if !g.has(id) {
 		// Nothing to do.
 		return
 	}
 
 	// Remove any attached edges.
-	for _, h := range g.HalfedgesFrom(x) {
-		g.RemoveEdge(h.Edge())
+	for _, h := range g.HalfedgesFrom(id) {
+		g.RemoveEdge(id, h.Twin().From().ID())
 	}
-	g.Node(id).SetHalfedge(nil) // Avoid memory leaks.
+	for _, o := range g.observers {
+		o.NodeRemoved(g.nodes[id])
+	}
+	g.nodes[id].SetHalfedge(nil) // Avoid memory leaks.
+	g.deleteNode(id)
+}
 
+// deleteNode deletes the node with the given id, which must be isolated, from
+// the graph and releases its ID and property values. The observers must have
+// been notified.
+func (g *Graph) deleteNode(id int64) {
 	delete(g.nodes, id)
-	if g.nextNodeID != 0 && id == g.nextNodeID {
+	if g.nextNodeID != 0 && id == g.nextNodeID-1 {
 		g.nextNodeID--
 	}
 	g.freeNodes[id] = struct{}{}
+	g.props.removeNode(id)
 }
 
 // addEdge adds a new edge between nodes identified by x.ID() and y.ID() and
@@ -244,7 +381,7 @@ func (g *Graph) addEdge(x, y graph.Node) (Halfedge, error) {
 		panic(fmt.Sprintf("dcel: trying to set a loop edge at node %d", x.ID()))
 	}
 
-	h := g.Halfedge(x, y)
+	h := g.Halfedge(x.ID(), y.ID())
 	if h != nil {
 		// Edge between x and y already exists, so return the halfedge.
 		return h, nil
@@ -252,42 +389,59 @@ func (g *Graph) addEdge(x, y graph.Node) (Halfedge, error) {
 
 	// Add any missing node.
 	var u, v Node
-	if !g.Has(x) {
+	if !g.has(x.ID()) {
 		u = g.AddNode(x.ID())
 	} else {
-		u = g.Node(x.ID())
+		u = g.nodes[x.ID()]
 	}
-	if !g.Has(y) {
+	if !g.has(y.ID()) {
 		v = g.AddNode(y.ID())
 	} else {
-		v = g.Node(y.ID())
+		v = g.nodes[y.ID()]
 	}
 
 	// Allocate a new edge and attach it to the graph.
-	e := g.newEdge()
+	e := g.newEdge(g.newEdgeID())
 	h1, h2 := e.Halfedges()
-	if err := attach(h1, u); err != nil {
+	in1, out1, err := attach(h1, u)
+	if err != nil {
+		g.releaseEdgeID(e.ID())
 		return nil, err
 	}
-	if err := attach(h2, v); err != nil {
+	in2, out2, err := attach(h2, v)
+	if err != nil {
 		detach(h1)
+		reset(h1)
+		reset(h2)
+		g.releaseEdgeID(e.ID())
 		return nil, err
 	}
 
 	id := e.ID()
 	g.edges[id] = e
 	delete(g.freeEdges, id)
-	g.nextEdgeID = max(g.nextEdgeID, id)
+	g.nextEdgeID = nextID(g.nextEdgeID, id)
 
+	for _, o := range g.observers {
+		o.EdgeAdded(e)
+	}
+	if in1 != nil {
+		g.reconnected(in1, h1)
+		g.reconnected(h2, out1)
+	}
+	if in2 != nil {
+		g.reconnected(in2, h2)
+		g.reconnected(h1, out2)
+	}
 	return h1, nil
 }
 
-// newEdge allocates a new, properly initialized Edge not connected to any
-// node.
-func (g *Graph) newEdge() Edge {
+// newEdge allocates a new, properly initialized Edge with the given id not
+// connected to any node.
+func (g *Graph) newEdge(id int64) Edge {
 	h1 := g.items.NewHalfedge()
 	h2 := g.items.NewHalfedge()
-	e := g.items.NewEdge(g.newEdgeID())
+	e := g.items.NewEdge(id)
 
 	h1.SetFrom(nil)
 	h2.SetFrom(nil)
@@ -311,7 +465,11 @@ func (g *Graph) newEdge() Edge {
 	return e
 }
 
-func attach(h Halfedge, u Node) error {
+// attach connects the halfedge h of a new edge to its from node u. If u is not
+// isolated, h is inserted into the halfedges around u after a free halfedge out
+// from u and its incoming neighbor in, so that in.Next() is h and
+// h.Twin().Next() is out, and in and out are returned.
+func attach(h Halfedge, u Node) (in, out Halfedge, err error) {
 	h.SetFrom(u)
 	if u.Halfedge() == nil {
 		// From node is isolated.
@@ -319,36 +477,36 @@ func attach(h Halfedge, u Node) error {
 		u.SetHalfedge(h)
 		h.SetPrev(h.Twin())
 		h.Twin().SetNext(h)
-		return nil
+		return nil, nil, nil
 	}
 
 	// From node is not isolated, so we must update its neighboring halfedges.
 	// First find a free (i.e., without an adjacent face) halfedge from u.
-	out := u.Halfedge()
+	out = u.Halfedge()
 	for {
 		if out.Face() == nil {
 			break
 		}
 		out = out.Twin().Next()
 		if out == u.Halfedge() {
-			return fmt.Errorf("dcel: no free halfedge from node %d", u.ID())
+			return nil, nil, fmt.Errorf("dcel: no free halfedge from node %d", u.ID())
 		}
 	}
 
 	// Adjust the connections.
-	in := out.Prev()
+	in = out.Prev()
 	in.SetNext(h)
 	h.SetPrev(in)
 	h.Twin().SetNext(out)
 	out.SetPrev(h.Twin())
 
-	return nil
+	return in, out, nil
 }
 
-// RemoveEdge removes the edge between nodes identified by e.From and e.To and
-// its adjacent faces from g.
-func (g *Graph) RemoveEdge(e graph.Edge) {
-	h := g.Halfedge(e.From(), e.To())
+// RemoveEdge removes the edge between nodes with IDs fid and tid and its
+// adjacent faces from g.
+func (g *Graph) RemoveEdge(fid, tid int64) {
+	h := g.Halfedge(fid, tid)
 	if h == nil {
 		// Nothing to do.
 		return
@@ -361,27 +519,56 @@ func (g *Graph) RemoveEdge(e graph.Edge) {
 	if h.Twin().Face() != nil {
 		g.RemoveFace(h.Twin().Face())
 	}
+	g.removeEdge(h)
+}
 
+// removeEdge removes the edge of the halfedge h from g. The halfedges of the
+// edge must not have adjacent faces.
+func (g *Graph) removeEdge(h Halfedge) {
 	// Detach both halfedges from their From nodes and update affected
-	// halfedges.
-	detach(h)
-	detach(h.Twin())
+	// halfedges. The halfedges can be cleared only after both have been
+	// detached because detaching one of them uses the connections of the
+	// other.
+	t := h.Twin()
+	e := h.Edge()
+	for _, o := range g.observers {
+		o.EdgeRemoved(e)
+	}
+	g.props.removeEdge(e.ID(), h, t)
+	in1, out1 := detach(h)
+	in2, out2 := detach(t)
+	reset(h)
+	reset(t)
+	if in1 != nil {
+		g.reconnected(in1, out1)
+	}
+	if in2 != nil {
+		g.reconnected(in2, out2)
+	}
+	g.deleteEdge(e.ID())
+}
 
-	id := h.Edge().ID()
+// deleteEdge deletes the edge with the given id, whose halfedges have been
+// disconnected, from the graph and releases its ID. The observers must have
+// been notified.
+func (g *Graph) deleteEdge(id int64) {
 	delete(g.edges, id)
-	if g.nextEdgeID != 0 && id == g.nextEdgeID {
+	if g.nextEdgeID != 0 && id == g.nextEdgeID-1 {
 		g.nextEdgeID--
 	}
 	g.freeEdges[id] = struct{}{}
 }
 
-func detach(h Halfedge) {
+// detach disconnects the halfedge h from its from node by linking its incoming
+// neighbor in to the outgoing neighbor out of its twin. If h is not the only
+// halfedge from its from node, in and out are returned.
+func detach(h Halfedge) (in, out Halfedge) {
 	if h.Face() != nil {
 		panic("dcel: face not removed before detaching halfedge")
 	}
 
-	out := h.Twin().Next()
-	in := h.Prev()
+	out = h.Twin().Next()
+	in = h.Prev()
 	from := h.From()
 	if from.Halfedge() == h {
 		// h is the halfedge referenced by its from node.
@@ -398,7 +585,14 @@ func detach(h Halfedge) {
 	}
 	out.SetPrev(in)
 	in.SetNext(out)
+	if out == h {
+		return nil, nil
+	}
+	return in, out
+}
 
+// reset clears the connections of a detached halfedge.
+func reset(h Halfedge) {
 	// Avoid memory leaks.
 	// TODO(vladimir-ch): Consider having a pool of reusable Edges.
 	h.SetFrom(nil)
@@ -409,7 +603,7 @@ func detach(h Halfedge) {
 }
 
 // HasFace returns whether a face with the given id exists in the graph.
-func (g *Graph) HasFace(id int) bool {
+func (g *Graph) HasFace(id int64) bool {
 	_, exists := g.faces[id]
 	return exists
 }
@@ -424,14 +618,19 @@ func (g *Graph) HasFace(id int) bool {
 //
 // AddFace panics if a face with the given id already exists in the graph or if
 // the length of nodes is less than 3.
-func (g *Graph) AddFace(id int, nodes ...graph.Node) error {
+func (g *Graph) AddFace(id int64, nodes ...graph.Node) error {
 	if g.HasFace(id) {
 		panic(fmt.Sprintf("dcel: face ID collision: %d", id))
 	}
 	if len(nodes) < 3 {
 		panic(fmt.Sprintf("dcel: cannot add face %d with only %d nodes", id, len(nodes)))
 	}
+	return g.addFace(id, nil, nodes)
+}
 
+// addFace adds the face f with the given id and with vertices given by nodes.
+// If f is nil, a new face is allocated.
+func (g *Graph) addFace(id int64, f Face, nodes []graph.Node) error {
 	// Check that the nodes are pair-wise distinct.
 	for i, x := range nodes {
 		for j := i + 1; j < len(nodes); j++ {
@@ -461,13 +660,15 @@ func (g *Graph) AddFace(id int, nodes ...graph.Node) error {
 	// neighbors.
 	for i, h1 := range hedges {
 		h2 := hedges[(i+1)%len(hedges)]
-		if err := reconnect(h1, h2); err != nil {
+		if err := g.reconnect(h1, h2); err != nil {
 			return err
 		}
 	}
 
 	// Allocate new face and set its halfedge.
-	f := g.items.NewFace(id)
+	if f == nil {
+		f = g.items.NewFace(id)
+	}
 	f.SetHalfedge(hedges[0])
 	// Set the face of adjacent halfedges.
 	for _, h := range hedges {
@@ -476,15 +677,19 @@ func (g *Graph) AddFace(id int, nodes ...graph.Node) error {
 
 	g.faces[id] = f
 	delete(g.freeFaces, id)
-	g.nextFaceID = max(g.nextFaceID, id)
+	g.nextFaceID = nextID(g.nextFaceID, id)
 
+	for _, o := range g.observers {
+		o.FaceAdded(f)
+	}
 	return nil
 }
 
 // reconnect adjusts the halfedges around the shared node between in and out so
-// that in.Next() == out and out.Prev() == in.
+// that in.Next() == out and out.Prev() == in. Observers are notified of each
+// pair of relinked halfedges.
 // It panics if in and out do not share a common node.
-func reconnect(in, out Halfedge) error {
+func (g *Graph) reconnect(in, out Halfedge) error {
 	if in.Twin().From() != out.From() {
 		panic("dcel.reconnect: halfedges are not connected")
 	}
@@ -530,6 +735,9 @@ func reconnect(in, out Halfedge) error {
 	outPrev.SetNext(bNext)
 	bNext.SetPrev(outPrev)
 
+	g.reconnected(in, out)
+	g.reconnected(b, inNext)
+	g.reconnected(outPrev, bNext)
 	return nil
 }
 
@@ -541,6 +749,10 @@ func (g *Graph) RemoveFace(f Face) {
 		return
 	}
 
+	for _, o := range g.observers {
+		o.FaceRemoved(f)
+	}
+
 	// Disconnect the face from its adjacent halfedges.
 	for _, h := range g.HalfedgesAround(f) {
 		h.SetFace(nil)
@@ -548,14 +760,15 @@ func (g *Graph) RemoveFace(f Face) {
 	f.SetHalfedge(nil)
 
 	delete(g.faces, id)
-	if g.nextFaceID != 0 && id == g.nextFaceID {
+	if g.nextFaceID != 0 && id == g.nextFaceID-1 {
 		g.nextFaceID--
 	}
 	g.freeFaces[id] = struct{}{}
+	g.props.removeFace(id)
 }
 
-func (g *Graph) newEdgeID() int {
-	if g.nextEdgeID != maxInt {
+func (g *Graph) newEdgeID() int64 {
+	if g.nextEdgeID != maxID {
 		id := g.nextEdgeID
 		g.nextEdgeID++
 		return id
@@ -565,12 +778,12 @@ func (g *Graph) newEdgeID() int {
 	for id := range g.freeEdges {
 		return id
 	}
-	if len(g.edges) == maxInt {
+	if int64(len(g.edges)) == maxID {
 		panic("dcel: graph too large")
 	}
 	// Resort to checking all positive integers to see if there is at least one
 	// unused.
-	for id := 0; id < maxInt; id++ {
+	for id := int64(0); id < maxID; id++ {
 		if _, exists := g.edges[id]; !exists {
 			return id
 		}
@@ -578,9 +791,17 @@ func (g *Graph) newEdgeID() int {
 	panic("dcel: no free edge ID")
 }
 
+// releaseEdgeID gives back the ID returned by newEdgeID for an edge that has
+// not been added to the graph.
+func (g *Graph) releaseEdgeID(id int64) {
+	if g.nextEdgeID != 0 && id == g.nextEdgeID-1 {
+		g.nextEdgeID--
+	}
+}
+
 // NewFaceID returns a new face id unique within the graph.
-func (g *Graph) NewFaceID() int {
-	if g.nextFaceID != maxInt {
+func (g *Graph) NewFaceID() int64 {
+	if g.nextFaceID != maxID {
 		id := g.nextFaceID
 		g.nextFaceID++
 		return id
@@ -590,12 +811,12 @@ func (g *Graph) NewFaceID() int {
 	for id := range g.freeFaces {
 		return id
 	}
-	if len(g.faces) == maxInt {
+	if int64(len(g.faces)) == maxID {
 		panic("dcel: graph too large")
 	}
 	// Resort to checking all positive integers to see if there is at least one
 	// unused.
-	for id := 0; id < maxInt; id++ {
+	for id := int64(0); id < maxID; id++ {
 		if _, exists := g.faces[id]; !exists {
 			return id
 		}
@@ -603,9 +824,9 @@ func (g *Graph) NewFaceID() int {
 	panic("dcel: no free face ID")
 }
 
-// HalfedgesFrom returns all halfedges whose From node is x.
-func (g *Graph) HalfedgesFrom(x graph.Node) []Halfedge {
-	u := g.Node(x.ID())
+// HalfedgesFrom returns all halfedges whose From node has the given id.
+func (g *Graph) HalfedgesFrom(id int64) []Halfedge {
+	u := g.nodes[id]
 	if u == nil {
 		// The node does not belong to the graph.
 		return nil
@@ -628,9 +849,9 @@ func (g *Graph) HalfedgesFrom(x graph.Node) []Halfedge {
 	return hedges
 }
 
-// HalfedgesTo returns all halfedges whose Twin.From node is x.
-func (g *Graph) HalfedgesTo(x graph.Node) []Halfedge {
-	u := g.Node(x.ID())
+// HalfedgesTo returns all halfedges whose Twin.From node has the given id.
+func (g *Graph) HalfedgesTo(id int64) []Halfedge {
+	u := g.nodes[id]
 	if u == nil {
 		// The node does not belong to the graph.
 		return nil
@@ -675,11 +896,98 @@ func (g *Graph) HalfedgesAround(f Face) []Halfedge {
 	return hedges
 }
 
-func max(a, b int) int {
+// Loop returns the halfedges of the loop that h belongs to, starting with h and
+// following Next. For a halfedge with an adjacent face it returns the
+// halfedges around the face, for a boundary halfedge it returns the halfedges
+// of the boundary loop.
+func (g *Graph) Loop(h Halfedge) []Halfedge {
+	if h == nil || h.From() == nil || g.nodes[h.From().ID()] != h.From() {
+		// The halfedge does not belong to the graph.
+		return nil
+	}
+	var hedges []Halfedge
+	for iter := h; ; {
+		hedges = append(hedges, iter)
+		iter = iter.Next()
+		if iter == h {
+			break
+		}
+	}
+	return hedges
+}
+
+// BoundaryLoops returns one halfedge from each boundary loop in the graph. A
+// boundary loop is a loop of halfedges without an adjacent face. The loops are
+// returned in the order of the smallest ID of their edges.
+func (g *Graph) BoundaryLoops() []Halfedge {
+	var (
+		loops []Halfedge
+		seen  = make(map[Halfedge]struct{})
+	)
+	for _, id := range g.edgeIDs() {
+		h1, h2 := g.edges[id].Halfedges()
+		for _, h := range [2]Halfedge{h1, h2} {
+			if h.Face() != nil {
+				continue
+			}
+			if _, ok := seen[h]; ok {
+				continue
+			}
+			for _, lh := range g.Loop(h) {
+				seen[lh] = struct{}{}
+			}
+			loops = append(loops, h)
+		}
+	}
+	return loops
+}
+
+// nodeIDs returns the IDs of the nodes in the graph in increasing order.
+func (g *Graph) nodeIDs() []int64 {
+	ids := make([]int64, 0, len(g.nodes))
+	for id := range g.nodes {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// faceIDs returns the IDs of the faces in the graph in increasing order.
+func (g *Graph) faceIDs() []int64 {
+	ids := make([]int64, 0, len(g.faces))
+	for id := range g.faces {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// edgeIDs returns the IDs of the edges in the graph in increasing order.
+func (g *Graph) edgeIDs() []int64 {
+	ids := make([]int64, 0, len(g.edges))
+	for id := range g.edges {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// nextID returns the new value of a counter of unused IDs after id has been
+// taken. The counter holds the smallest ID larger than all IDs in use, or
+// maxID if all IDs up to maxID have been used and the free IDs will have to
+// be looked up.
+func nextID(next, id int64) int64 {
+	if id == maxID {
+		return maxID
+	}
+	return max(next, id+1)
+}
+
+func max(a, b int64) int64 {
 	if a < b {
 		return b
 	}
 	return a
 }
 
-const maxInt int = int(^uint(0) >> 1)
+const maxID int64 = math.MaxInt64
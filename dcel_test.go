@@ -1,10 +1,17 @@
 package dcel
 
-import "testing"
+import (
+	"math"
+	"testing"
 
-type NodeID int
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/graph/path"
+	"gonum.org/v1/gonum/graph/simple"
+)
 
-func (n NodeID) ID() int { return int(n) }
+type NodeID int64
+
+func (n NodeID) ID() int64 { return int64(n) }
 
 func TestTriangle(t *testing.T) {
 	g := New(nil)
@@ -20,13 +27,13 @@ func TestTriangle(t *testing.T) {
 	}
 
 	for i := 0; i < 3; i++ {
-		h := g.Halfedge(NodeID(i), NodeID((i+1)%3))
+		h := g.Halfedge(int64(i), int64((i+1)%3))
 		if h == nil {
 			t.Error("dcel: halfedge does not exist")
 		}
 	}
 
-	if len(g.Nodes()) != 3 {
+	if g.Nodes().Len() != 3 {
 		t.Error("dcel: graph with one triangle has wrong number of nodes")
 	}
 }
@@ -53,13 +60,13 @@ func TestTwoTriangles(t *testing.T) {
 	}
 
 	for i := 0; i < 3; i++ {
-		h := g.Halfedge(NodeID(i), NodeID((i+1)%3))
+		h := g.Halfedge(int64(i), int64((i+1)%3))
 		if h == nil {
 			t.Error("dcel: halfedge does not exist")
 		}
 	}
 
-	if len(g.Nodes()) != 4 {
+	if g.Nodes().Len() != 4 {
 		t.Error("dcel: graph with two triangles has wrong number of nodes")
 	}
 }
@@ -86,13 +93,187 @@ func TestTriangleSquare(t *testing.T) {
 	}
 
 	for i := 0; i < 3; i++ {
-		h := g.Halfedge(NodeID(i), NodeID((i+1)%3))
+		h := g.Halfedge(int64(i), int64((i+1)%3))
 		if h == nil {
 			t.Error("dcel: halfedge does not exist")
 		}
 	}
 
-	if len(g.Nodes()) != 5 {
+	if g.Nodes().Len() != 5 {
 		t.Error("dcel: graph with triangle and square has wrong number of nodes")
 	}
 }
+
+func TestNewIDs(t *testing.T) {
+	g := New(nil)
+
+	err := g.AddFace(0, NodeID(0), NodeID(1), NodeID(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if id := g.NewNodeID(); g.Node(id) != nil {
+		t.Errorf("dcel: new node ID %d already in use", id)
+	}
+	id := g.NewFaceID()
+	if g.Face(id) != nil {
+		t.Fatalf("dcel: new face ID %d already in use", id)
+	}
+	err = g.AddFace(id, NodeID(2), NodeID(1), NodeID(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Removing the face with the largest ID makes its ID available again.
+	g.RemoveFace(g.Face(id))
+	if got := g.NewFaceID(); got != id {
+		t.Errorf("dcel: unexpected new face ID after removal: got %d, want %d", got, id)
+	}
+}
+
+func TestBoundaryLoops(t *testing.T) {
+	g := New(nil)
+
+	err := g.AddFace(0, NodeID(0), NodeID(1), NodeID(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = g.AddFace(1, NodeID(2), NodeID(1), NodeID(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loops := g.BoundaryLoops()
+	if len(loops) != 1 {
+		t.Fatalf("dcel: wrong number of boundary loops: %d", len(loops))
+	}
+	if len(g.Loop(loops[0])) != 4 {
+		t.Error("dcel: wrong number of halfedges in boundary loop")
+	}
+}
+
+func TestRemove(t *testing.T) {
+	g := New(nil)
+
+	err := g.AddFace(0, NodeID(0), NodeID(1), NodeID(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = g.AddFace(1, NodeID(2), NodeID(1), NodeID(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g.RemoveEdge(1, 2)
+	if g.HasEdgeBetween(1, 2) {
+		t.Error("dcel: removed edge still exists")
+	}
+	if len(g.Faces()) != 0 {
+		t.Error("dcel: faces adjacent to removed edge still exist")
+	}
+	if g.Edges().Len() != 4 {
+		t.Errorf("dcel: wrong number of edges: %d", g.Edges().Len())
+	}
+	if len(g.Loop(g.Halfedge(0, 1))) != 4 {
+		t.Error("dcel: wrong boundary loop after edge removal")
+	}
+
+	g.RemoveNode(0)
+	if g.Node(0) != nil {
+		t.Error("dcel: removed node still exists")
+	}
+	if g.Edges().Len() != 2 {
+		t.Errorf("dcel: wrong number of edges: %d", g.Edges().Len())
+	}
+	if g.From(1).Len() != 1 || g.From(2).Len() != 1 {
+		t.Error("dcel: wrong neighbors after node removal")
+	}
+}
+
+func TestShortestPath(t *testing.T) {
+	g := newTorus(4, 4)
+
+	pt := path.DijkstraFrom(g.Node(0), g)
+	if _, w := pt.To(10); w != 4 {
+		t.Errorf("dcel: wrong shortest path length: got %v, want 4", w)
+	}
+}
+
+func TestWeight(t *testing.T) {
+	g := New(nil)
+	err := g.AddFace(0, NodeID(0), NodeID(1), NodeID(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w, ok := g.Weight(0, 1); !ok || w != 1 {
+		t.Errorf("dcel: wrong default weight: %v", w)
+	}
+	g.Halfedge(0, 1).Edge().(WeightedEdge).SetWeight(5)
+	if w, _ := g.Weight(1, 0); w != 5 {
+		t.Errorf("dcel: wrong stored weight: got %v, want 5", w)
+	}
+	if w := g.WeightedEdge(0, 1).ReversedEdge().(Edge).Weight(); w != 5 {
+		t.Errorf("dcel: wrong weight of reversed edge: got %v, want 5", w)
+	}
+	e := g.EdgeBetween(1, 2)
+	if e != g.Halfedge(1, 2).Edge() {
+		t.Error("dcel: EdgeBetween does not return the stored edge")
+	}
+	e.(WeightedEdge).SetWeight(6)
+	if w, _ := g.Weight(1, 2); w != 6 {
+		t.Errorf("dcel: wrong weight set through EdgeBetween: got %v, want 6", w)
+	}
+	e.ReversedEdge().(WeightedEdge).SetWeight(7)
+	if w, _ := g.Weight(1, 2); w != 7 {
+		t.Errorf("dcel: wrong weight set through reversed edge: got %v, want 7", w)
+	}
+
+	g = NewWeighted(PointBase{}, EdgeLength)
+	for i, p := range []Point{{0, 0, 0}, {3, 0, 0}, {3, 4, 0}, {0, 4, 0}} {
+		g.AddNode(int64(i)).(PointNode).SetPoint(p)
+	}
+	err = g.AddFace(0, NodeID(0), NodeID(1), NodeID(2), NodeID(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w := g.WeightedEdge(1, 2).Weight(); w != 4 {
+		t.Errorf("dcel: wrong edge length: got %v, want 4", w)
+	}
+	r := g.WeightedEdge(0, 1).ReversedEdge()
+	if w := r.(Edge).Weight(); w != 3 {
+		t.Errorf("dcel: wrong edge length of reversed edge: got %v, want 3", w)
+	}
+	if r.From().ID() != 1 || r.To().ID() != 0 {
+		t.Error("dcel: reversed edge not reversed")
+	}
+	_, w := path.DijkstraFrom(g.Node(0), g).To(2)
+	if w != 7 {
+		t.Errorf("dcel: wrong shortest path length: got %v, want 7", w)
+	}
+	if w, ok := g.Weight(0, 2); ok || !math.IsInf(w, 1) {
+		t.Errorf("dcel: wrong weight between unconnected nodes: %v", w)
+	}
+
+	// The reversal of an edge that does not reverse to an Edge.
+	g = NewWeighted(plainReversalItems{}, func(Edge) float64 { return 2 })
+	err = g.AddFace(0, NodeID(0), NodeID(1), NodeID(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r = g.WeightedEdge(0, 1).ReversedEdge()
+	if w := r.(graph.WeightedEdge).Weight(); w != 2 {
+		t.Errorf("dcel: wrong weight of reversed edge: got %v, want 2", w)
+	}
+	if r.From().ID() != 1 || r.To().ID() != 0 {
+		t.Error("dcel: reversed edge not reversed")
+	}
+}
+
+// plainReversalItems allocates edges whose reversals are not Edges.
+type plainReversalItems struct{ Base }
+
+func (plainReversalItems) NewEdge(id int64) Edge { return plainReversalEdge{NewBaseEdge(id)} }
+
+type plainReversalEdge struct{ *BaseEdge }
+
+func (e plainReversalEdge) ReversedEdge() graph.Edge { return simple.Edge{F: e.To(), T: e.From()} }
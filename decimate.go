@@ -0,0 +1,377 @@
+package dcel
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+)
+
+// DecimateOptions are options for Decimate.
+type DecimateOptions struct {
+	// MaxError is the largest quadric error of an edge collapse. If it is
+	// zero, the error is not limited.
+	MaxError float64
+}
+
+// Decimate simplifies the triangle mesh formed by the faces of g by collapsing
+// edges until g has at most targetFaces faces or until no edge can be
+// collapsed. The edges are collapsed in the order of increasing
+// Garland–Heckbert quadric error and the node that remains is moved to the
+// position that minimizes the error.
+//
+// An edge is collapsed only if the nodes around it form manifold fans, if the
+// nodes adjacent to both of its end nodes are only the opposite nodes of its
+// faces and if no face flips over. Boundary edges and edges between two
+// boundary nodes are not collapsed and boundary nodes keep their positions.
+// The faces that remain keep their IDs and property values.
+//
+// If a node of g does not carry a position or if a face of g is not a
+// triangle, an error is returned and g is not modified.
+func Decimate(g *Graph, targetFaces int, opts DecimateOptions) error {
+	for _, id := range g.nodeIDs() {
+		if _, ok := position(g.nodes[id]); !ok {
+			return fmt.Errorf("dcel: node %d does not carry a position", id)
+		}
+	}
+	if err := triangles(g); err != nil {
+		return err
+	}
+
+	d := decimation{
+		g:        g,
+		quadrics: make(map[int64]quadric),
+		stamps:   make(map[int64]int),
+	}
+	for _, id := range g.faceIDs() {
+		h := g.faces[id].Halfedge()
+		a, b, c := point(h.From()), point(h.Next().From()), point(h.Prev().From())
+		n := b.Sub(a).Cross(c.Sub(a))
+		if l := n.Norm(); l > 0 {
+			n = n.Scale(1 / l)
+		} else {
+			// The plane of a degenerate face is undefined.
+			continue
+		}
+		q := planeQuadric(n, -n.Dot(a))
+		for _, u := range []Node{h.From(), h.Next().From(), h.Prev().From()} {
+			d.quadrics[u.ID()] = d.quadrics[u.ID()].add(q)
+		}
+	}
+	for _, id := range g.edgeIDs() {
+		h, _ := g.edges[id].Halfedges()
+		d.push(h.From(), h.Twin().From())
+	}
+
+	for len(g.faces) > targetFaces && d.queue.Len() > 0 {
+		c := heap.Pop(&d.queue).(collapse)
+		if opts.MaxError > 0 && c.cost > opts.MaxError {
+			break
+		}
+		k, r := g.nodes[c.k], g.nodes[c.r]
+		if k == nil || r == nil || d.stamps[c.k] != c.sk || d.stamps[c.r] != c.sr {
+			// The candidate is stale.
+			continue
+		}
+		if !d.collapsible(k, r, c.p) {
+			continue
+		}
+		d.collapse(k, r, c.p)
+	}
+	return nil
+}
+
+// decimation holds the state of Decimate.
+type decimation struct {
+	g *Graph
+
+	// quadrics are the error quadrics of the nodes.
+	quadrics map[int64]quadric
+	// stamps are incremented each time a node is moved so that collapse
+	// candidates computed earlier can be recognized as stale.
+	stamps map[int64]int
+	queue  collapses
+}
+
+// push adds to the queue the candidate collapse of the edge between u and v.
+func (d *decimation) push(u, v Node) {
+	bu, bv := onBoundary(u), onBoundary(v)
+	if bu && bv {
+		return
+	}
+	if bv {
+		// Keep the boundary node.
+		u, v, bu = v, u, true
+	}
+	q := d.quadrics[u.ID()].add(d.quadrics[v.ID()])
+
+	p := point(u)
+	if !bu {
+		if m, ok := q.minimize(); ok {
+			p = m
+		} else {
+			// Choose the best of the end nodes and their midpoint.
+			a, b := point(u), point(v)
+			for _, x := range []Point{b, a.Add(b).Scale(0.5)} {
+				if q.eval(x) < q.eval(p) {
+					p = x
+				}
+			}
+		}
+	}
+	heap.Push(&d.queue, collapse{
+		k: u.ID(), r: v.ID(),
+		sk: d.stamps[u.ID()], sr: d.stamps[v.ID()],
+		p:    p,
+		cost: q.eval(p),
+	})
+}
+
+// collapsible returns whether the edge between k and r can be collapsed into
+// the node k at the position p.
+func (d *decimation) collapsible(k, r Node, p Point) bool {
+	h := d.g.Halfedge(k.ID(), r.ID())
+	if h == nil || h.Face() == nil || h.Twin().Face() == nil {
+		return false
+	}
+	if !manifoldNode(k) || !manifoldNode(r) || onBoundary(r) {
+		return false
+	}
+
+	// Check the link condition.
+	a, b := h.Prev().From(), h.Twin().Prev().From()
+	if a == b {
+		return false
+	}
+	neighbors := make(map[int64]bool)
+	for _, hk := range d.g.HalfedgesFrom(k.ID()) {
+		neighbors[hk.Twin().From().ID()] = true
+	}
+	for _, hr := range d.g.HalfedgesFrom(r.ID()) {
+		w := hr.Twin().From()
+		if neighbors[w.ID()] && w != a && w != b {
+			return false
+		}
+	}
+	// Avoid opposite nodes with less than three interior or two boundary
+	// neighbors.
+	for _, w := range []Node{a, b} {
+		n := len(d.g.HalfedgesFrom(w.ID()))
+		if n <= 2 || n == 3 && !onBoundary(w) {
+			return false
+		}
+	}
+
+	// Check that no face around k or r other than those of the edge flips.
+	for _, u := range []Node{k, r} {
+		for _, hu := range d.g.HalfedgesFrom(u.ID()) {
+			f := hu.Face()
+			if f == nil || f == h.Face() || f == h.Twin().Face() {
+				continue
+			}
+			x, y, z := point(u), point(hu.Next().From()), point(hu.Prev().From())
+			before := y.Sub(x).Cross(z.Sub(x))
+			after := y.Sub(p).Cross(z.Sub(p))
+			if before.Dot(after) <= 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// collapse collapses the edge between k and r into the node k at position p.
+// The faces of the edge, the edge, the edges from r to the opposite nodes of
+// the faces and r are removed. The other halfedges from r are moved to k and
+// the halfedges from the opposite nodes to k take the place of the removed
+// halfedges in the faces beyond, so that the faces that remain are not
+// removed and added back. The removed edges and r are reported to the
+// observers after the remaining halfedges have been relinked and the removed
+// elements deleted from the graph, but before their halfedges are cleared.
+// The edge must satisfy collapsible.
+func (d *decimation) collapse(k, r Node, p Point) {
+	g := d.g
+
+	// The edge has the faces k, r, a and r, k, b.
+	h := g.Halfedge(k.ID(), r.ID())
+	t := h.Twin()
+	ra, ak := h.Next(), h.Prev()
+	kb, br := t.Next(), t.Prev()
+	ar, rb := ra.Twin(), br.Twin()
+	a, b := ak.From(), br.From()
+	var moved []Halfedge
+	for _, hr := range g.HalfedgesFrom(r.ID()) {
+		if hr != t && hr != ra && hr != rb {
+			moved = append(moved, hr)
+		}
+	}
+
+	g.RemoveFace(h.Face())
+	g.RemoveFace(t.Face())
+
+	// Replace ar by ak and rb by kb in the faces beyond the edges from r to
+	// the opposite nodes.
+	for _, x := range [][2]Halfedge{{ar, ak}, {rb, kb}} {
+		old, hn := x[0], x[1]
+		next, prev, f := old.Next(), old.Prev(), old.Face()
+		hn.SetNext(next)
+		next.SetPrev(hn)
+		hn.SetPrev(prev)
+		prev.SetNext(hn)
+		hn.SetFace(f)
+		old.SetFace(nil)
+		if f.Halfedge() == old {
+			f.SetHalfedge(hn)
+		}
+	}
+	for _, hr := range moved {
+		hr.SetFrom(k)
+	}
+	if a.Halfedge() == ar {
+		a.SetHalfedge(ak)
+	}
+	if b.Halfedge() == br {
+		b.SetHalfedge(kb.Twin())
+	}
+	if k.Halfedge() == h {
+		k.SetHalfedge(kb)
+	}
+	r.SetHalfedge(nil)
+
+	for _, x := range []Halfedge{h, ra, br} {
+		e := x.Edge()
+		g.props.removeEdge(e.ID(), x, x.Twin())
+		g.deleteEdge(e.ID())
+		for _, o := range g.observers {
+			o.EdgeRemoved(e)
+		}
+		reset(x.Twin())
+		reset(x)
+	}
+	g.deleteNode(r.ID())
+	for _, o := range g.observers {
+		o.NodeRemoved(r)
+	}
+
+	g.reconnected(ak.Prev(), ak)
+	g.reconnected(kb, kb.Next())
+	for _, hk := range append(moved, kb) {
+		g.reconnected(hk.Prev(), hk)
+	}
+
+	k.(PointNode).SetPoint(p)
+	d.quadrics[k.ID()] = d.quadrics[k.ID()].add(d.quadrics[r.ID()])
+	delete(d.quadrics, r.ID())
+	delete(d.stamps, r.ID())
+	d.stamps[k.ID()]++
+	for _, hk := range g.HalfedgesFrom(k.ID()) {
+		d.push(k, hk.Twin().From())
+	}
+}
+
+// onBoundary returns whether u has an outgoing halfedge without an adjacent
+// face.
+func onBoundary(u Node) bool {
+	if u.Halfedge() == nil {
+		return false
+	}
+	for h := u.Halfedge(); ; {
+		if h.Face() == nil {
+			return true
+		}
+		h = h.Twin().Next()
+		if h == u.Halfedge() {
+			return false
+		}
+	}
+}
+
+// collapse is a candidate edge collapse of the node r into the node k.
+type collapse struct {
+	k, r   int64
+	sk, sr int // Stamps of k and r.
+
+	p    Point
+	cost float64
+}
+
+// collapses is a min-heap of collapses ordered by cost.
+type collapses []collapse
+
+func (c collapses) Len() int            { return len(c) }
+func (c collapses) Less(i, j int) bool  { return c[i].cost < c[j].cost }
+func (c collapses) Swap(i, j int)       { c[i], c[j] = c[j], c[i] }
+func (c *collapses) Push(x interface{}) { *c = append(*c, x.(collapse)) }
+func (c *collapses) Pop() interface{} {
+	old := *c
+	x := old[len(old)-1]
+	*c = old[:len(old)-1]
+	return x
+}
+
+// quadric is a symmetric 4×4 matrix of an error quadric stored as its upper
+// triangle in row-major order.
+type quadric [10]float64
+
+// planeQuadric returns the quadric of the squared distance from the plane
+// n·x + d = 0 where n is a unit vector.
+func planeQuadric(n Point, d float64) quadric {
+	return quadric{
+		n.X * n.X, n.X * n.Y, n.X * n.Z, n.X * d,
+		n.Y * n.Y, n.Y * n.Z, n.Y * d,
+		n.Z * n.Z, n.Z * d,
+		d * d,
+	}
+}
+
+// add returns the sum of q and r.
+func (q quadric) add(r quadric) quadric {
+	for i := range q {
+		q[i] += r[i]
+	}
+	return q
+}
+
+// eval returns the error of q at p.
+func (q quadric) eval(p Point) float64 {
+	x, y, z := p.X, p.Y, p.Z
+	return q[0]*x*x + 2*q[1]*x*y + 2*q[2]*x*z + 2*q[3]*x +
+		q[4]*y*y + 2*q[5]*y*z + 2*q[6]*y +
+		q[7]*z*z + 2*q[8]*z +
+		q[9]
+}
+
+// minimize returns the point at which q is minimal. If the minimum is not
+// unique, it returns false.
+func (q quadric) minimize() (Point, bool) {
+	// Solve the 3×3 system by Cramer's rule.
+	a := [3][3]float64{
+		{q[0], q[1], q[2]},
+		{q[1], q[4], q[5]},
+		{q[2], q[5], q[7]},
+	}
+	b := [3]float64{-q[3], -q[6], -q[8]}
+	det3 := func(m [3][3]float64) float64 {
+		return m[0][0]*(m[1][1]*m[2][2]-m[1][2]*m[2][1]) -
+			m[0][1]*(m[1][0]*m[2][2]-m[1][2]*m[2][0]) +
+			m[0][2]*(m[1][0]*m[2][1]-m[1][1]*m[2][0])
+	}
+	det := det3(a)
+	var scale float64
+	for _, row := range a {
+		for _, v := range row {
+			scale = math.Max(scale, math.Abs(v))
+		}
+	}
+	if math.Abs(det) <= 1e-10*scale*scale*scale {
+		return Point{}, false
+	}
+	var x [3]float64
+	for i := range x {
+		m := a
+		for j := range m {
+			m[j][i] = b[j]
+		}
+		x[i] = det3(m) / det
+	}
+	return Point{X: x[0], Y: x[1], Z: x[2]}, true
+}
@@ -0,0 +1,185 @@
+package dcel
+
+import (
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/graph"
+)
+
+func TestDecimateSphere(t *testing.T) {
+	octahedron := newMesh(
+		[]Point{{X: 1}, {Y: 1}, {X: -1}, {Y: -1}, {Z: 1}, {Z: -1}},
+		[][]int{{0, 1, 4}, {1, 2, 4}, {2, 3, 4}, {3, 0, 4}, {1, 0, 5}, {2, 1, 5}, {3, 2, 5}, {0, 3, 5}},
+	)
+	g, err := octahedron.LoopSubdivide(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Project the nodes onto the unit sphere.
+	for _, u := range graph.NodesOf(g.Nodes()) {
+		pu := u.(PointNode)
+		pu.SetPoint(pu.Point().Scale(1 / pu.Point().Norm()))
+	}
+
+	if err = Decimate(g, 100, DecimateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(g.Faces()); n > 100 || n < 98 {
+		t.Errorf("dcel: wrong number of faces: %d", n)
+	}
+	checkClosed(t, g, len(g.Faces()), 3)
+	c := g.Topology().Components
+	if len(c) != 1 || !c[0].Manifold || c[0].EulerCharacteristic != 2 {
+		t.Errorf("dcel: wrong topology: %+v", c)
+	}
+	for _, u := range graph.NodesOf(g.Nodes()) {
+		if r := point(u.(Node)).Norm(); math.Abs(r-1) > 0.1 {
+			t.Errorf("dcel: node %d far from the sphere: %v", u.ID(), r)
+		}
+	}
+
+	// Collapses on a sphere have a non-zero error.
+	n := len(g.Faces())
+	if err = Decimate(g, 0, DecimateOptions{MaxError: 1e-12}); err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Faces()) != n {
+		t.Errorf("dcel: unexpected collapse: %d faces", len(g.Faces()))
+	}
+}
+
+func TestDecimatePlane(t *testing.T) {
+	var (
+		pts   []Point
+		faces [][]int
+	)
+	const n = 10
+	for j := 0; j <= n; j++ {
+		for i := 0; i <= n; i++ {
+			pts = append(pts, Point{X: float64(i), Y: float64(j)})
+		}
+	}
+	for j := 0; j < n; j++ {
+		for i := 0; i < n; i++ {
+			a := j*(n+1) + i
+			faces = append(faces, []int{a, a + 1, a + n + 2}, []int{a, a + n + 2, a + n + 1})
+		}
+	}
+	g := newMesh(pts, faces)
+	boundary := len(g.Loop(g.BoundaryLoops()[0]))
+
+	if err := Decimate(g, 0, DecimateOptions{MaxError: 1e-12}); err != nil {
+		t.Fatal(err)
+	}
+	// Only the boundary nodes remain.
+	if g.Nodes().Len() != boundary || len(g.Faces()) != boundary-2 {
+		t.Errorf("dcel: wrong size: %d nodes, %d faces", g.Nodes().Len(), len(g.Faces()))
+	}
+	var total float64
+	for _, f := range g.Faces() {
+		a := faceArea(g, f)
+		if a <= 0 {
+			t.Errorf("dcel: face %d is not counterclockwise", f.ID())
+		}
+		total += a
+	}
+	if math.Abs(total-n*n) > 1e-9 {
+		t.Errorf("dcel: wrong area: %v", total)
+	}
+	for _, u := range graph.NodesOf(g.Nodes()) {
+		if point(u.(Node)) != pts[u.ID()] {
+			t.Errorf("dcel: boundary node %d moved", u.ID())
+		}
+	}
+
+	if err := Decimate(New(nil), 0, DecimateOptions{}); err != nil {
+		t.Errorf("dcel: unexpected error for an empty graph: %v", err)
+	}
+	if err := Decimate(newMesh(rect(0, 0, 1, 1), [][]int{{0, 1, 2, 3}}), 0, DecimateOptions{}); err == nil {
+		t.Error("dcel: expected error for a quadrilateral")
+	}
+}
+
+func TestDecimateObserver(t *testing.T) {
+	var (
+		pts   []Point
+		faces [][]int
+	)
+	const n = 6
+	for j := 0; j <= n; j++ {
+		for i := 0; i <= n; i++ {
+			pts = append(pts, Point{X: float64(i), Y: float64(j)})
+		}
+	}
+	for j := 0; j < n; j++ {
+		for i := 0; i < n; i++ {
+			a := j*(n+1) + i
+			faces = append(faces, []int{a, a + 1, a + n + 2}, []int{a, a + n + 2, a + n + 1})
+		}
+	}
+	g := newMesh(pts, faces)
+	r := &recorder{g: g}
+	rp := &replayer{next: make(map[Halfedge]Halfedge)}
+	for _, id := range g.edgeIDs() {
+		rp.EdgeAdded(g.edges[id])
+	}
+	g.AddObserver(r)
+	g.AddObserver(rp)
+	g.AddObserver(&collapseChecker{t: t, g: g})
+
+	if err := Decimate(g, 0, DecimateOptions{MaxError: 1e-12}); err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Faces()) == 2*n*n {
+		t.Fatal("dcel: no edge collapsed")
+	}
+	// Only the faces of the collapsed edges are removed and no faces or
+	// edges are added back.
+	removed := 0
+	for _, ev := range r.events {
+		switch ev[:2] {
+		case "+f", "+e", "+n":
+			t.Errorf("dcel: unexpected event %s", ev)
+		case "-f":
+			removed++
+		}
+	}
+	if removed != 2*n*n-len(g.Faces()) {
+		t.Errorf("dcel: %d faces reported removed, want %d", removed, 2*n*n-len(g.Faces()))
+	}
+	rp.check(t, g)
+	for _, f := range g.Faces() {
+		for _, h := range g.HalfedgesAround(f) {
+			if h.Face() != f || h.Next().Prev() != h || h.Twin().Twin() != h || g.nodes[h.From().ID()] != h.From() {
+				t.Fatalf("dcel: inconsistent halfedge of face %d", f.ID())
+			}
+		}
+	}
+}
+
+// collapseChecker is an Observer that checks that the edges and nodes removed
+// by an edge collapse are reported after the graph has been relinked.
+type collapseChecker struct {
+	NopObserver
+	t *testing.T
+	g *Graph
+}
+
+func (c *collapseChecker) EdgeRemoved(e Edge) {
+	h1, h2 := e.Halfedges()
+	if c.g.edges[e.ID()] != nil || h1.Face() != nil || h2.Face() != nil {
+		c.t.Errorf("dcel: edge %d reported before it was removed", e.ID())
+	}
+	for _, h := range [2]Halfedge{h1, h2} {
+		if n := h.From().Halfedge(); n == h1 || n == h2 {
+			c.t.Errorf("dcel: edge %d reported before node %d was relinked", e.ID(), h.From().ID())
+		}
+	}
+}
+
+func (c *collapseChecker) NodeRemoved(u Node) {
+	if c.g.nodes[u.ID()] != nil || u.Halfedge() != nil {
+		c.t.Errorf("dcel: node %d reported before it was removed", u.ID())
+	}
+}
@@ -0,0 +1,430 @@
+package dcel
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Triangulation is a Delaunay triangulation of points in the XY plane stored
+// in a Graph. The nodes of the graph carry the positions of the points and
+// its faces are triangles with counterclockwise orientation. The boundary of
+// the triangulation is the convex hull of the points.
+//
+// The graph of a Triangulation must not be modified other than through the
+// methods of Triangulation.
+type Triangulation struct {
+	g *Graph
+
+	// constrained is the set of constrained edges keyed by the IDs of their
+	// end nodes as returned by edgeKey.
+	constrained map[[2]int64]bool
+
+	// last is a face near the last inserted point. Point location starts
+	// from it.
+	last Face
+	// steps counts the steps of point location and it is used to vary the
+	// order in which the edges of a face are tested.
+	steps int
+}
+
+// NewDelaunay returns the Delaunay triangulation of points computed by
+// incremental insertion with the Bowyer-Watson algorithm. The points are
+// inserted in the order along a Hilbert curve so that consecutive points are
+// close to each other and point location is fast. The node with ID i
+// carries the position points[i]. Only the X and Y coordinates are used for
+// computing the triangulation.
+//
+// If items is nil, PointBase will be used. NewDelaunay panics if items does not
+// allocate nodes that implement PointNode.
+//
+// If there are less than three points, if all points are collinear or if two
+// points are equal, an error is returned.
+func NewDelaunay(items Items, points []Point) (*Triangulation, error) {
+	if items == nil {
+		items = PointBase{}
+	}
+	if len(points) < 3 {
+		return nil, fmt.Errorf("dcel: cannot triangulate %d points", len(points))
+	}
+
+	order := hilbertOrder(points)
+
+	// Find three points that are not collinear and use them as the initial
+	// triangle.
+	i0 := order[0]
+	i1 := -1
+	for _, i := range order[1:] {
+		if !samePosition(points[i], points[i0]) {
+			i1 = i
+			break
+		}
+	}
+	if i1 < 0 {
+		return nil, errors.New("dcel: cannot triangulate, all points are equal")
+	}
+	i2 := -1
+	for _, i := range order[1:] {
+		if orient(points[i0], points[i1], points[i]) != 0 {
+			i2 = i
+			break
+		}
+	}
+	if i2 < 0 {
+		return nil, errors.New("dcel: cannot triangulate, all points are collinear")
+	}
+
+	t := &Triangulation{
+		g:           New(items),
+		constrained: make(map[[2]int64]bool),
+	}
+	for _, i := range []int{i0, i1, i2} {
+		addPointNode(t.g, int64(i), points[i])
+	}
+	if orient(points[i0], points[i1], points[i2]) < 0 {
+		i1, i2 = i2, i1
+	}
+	id := t.g.NewFaceID()
+	err := t.g.AddFace(id, nodeID(i0), nodeID(i1), nodeID(i2))
+	if err != nil {
+		panic(err)
+	}
+	t.last = t.g.Face(id)
+
+	for _, i := range order {
+		if i == i0 || i == i1 || i == i2 {
+			continue
+		}
+		if _, err := t.insert(int64(i), points[i]); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// hilbertOrder returns the indices of points sorted by their position along a
+// Hilbert curve that covers the bounding box of the points.
+func hilbertOrder(points []Point) []int {
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, p := range points {
+		minX, maxX = math.Min(minX, p.X), math.Max(maxX, p.X)
+		minY, maxY = math.Min(minY, p.Y), math.Max(maxY, p.Y)
+	}
+	const n = 1 << 16
+	scale := (n - 1) / math.Max(maxX-minX, maxY-minY)
+	if math.IsInf(scale, 0) || math.IsNaN(scale) {
+		scale = 0
+	}
+	keys := make([]uint64, len(points))
+	order := make([]int, len(points))
+	for i, p := range points {
+		keys[i] = hilbertIndex(n, uint64((p.X-minX)*scale), uint64((p.Y-minY)*scale))
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return keys[order[i]] < keys[order[j]] })
+	return order
+}
+
+// hilbertIndex returns the distance along the Hilbert curve that fills the n×n
+// grid of the cell with coordinates x and y. n must be a power of two.
+func hilbertIndex(n, x, y uint64) uint64 {
+	var d uint64
+	for s := n / 2; s > 0; s /= 2 {
+		var rx, ry uint64
+		if x&s != 0 {
+			rx = 1
+		}
+		if y&s != 0 {
+			ry = 1
+		}
+		d += s * s * ((3 * rx) ^ ry)
+		// Rotate the quadrant.
+		if ry == 0 {
+			if rx == 1 {
+				x = s - 1 - x
+				y = s - 1 - y
+			}
+			x, y = y, x
+		}
+	}
+	return d
+}
+
+// Graph returns the graph that stores the triangulation.
+func (t *Triangulation) Graph() *Graph { return t.g }
+
+// Insert inserts a new point into the triangulation and returns its node. The
+// node is allocated with an ID given by NewNodeID. If the point is equal to an
+// existing node, an error is returned.
+func (t *Triangulation) Insert(p Point) (Node, error) {
+	return t.insert(t.g.NewNodeID(), p)
+}
+
+// Hull returns the nodes on the boundary of the convex hull of the
+// triangulation in counterclockwise order. Nodes that lie in the interior of
+// a hull edge are included.
+func (t *Triangulation) Hull() []Node {
+	loops := t.g.BoundaryLoops()
+	if len(loops) == 0 {
+		return nil
+	}
+	// The boundary loop runs in clockwise order.
+	loop := t.g.Loop(loops[0])
+	hull := make([]Node, len(loop))
+	for i, h := range loop {
+		hull[len(loop)-1-i] = h.From()
+	}
+	return hull
+}
+
+// insert inserts the point p as a node with the given id. The triangles whose
+// circumcircle contains p form a cavity that is replaced by a fan of triangles
+// around the new node. When p lies outside the convex hull, the cavity also
+// includes the hull edges visible from p. The cavity does not extend across
+// constrained edges unless p lies on the edge in which case the edge is split.
+func (t *Triangulation) insert(id int64, p Point) (Node, error) {
+	f, ghost := t.locate(p)
+	var (
+		split  Halfedge
+		sa, sb int64
+	)
+	if f != nil {
+		for _, h := range t.g.HalfedgesAround(f) {
+			if samePosition(point(h.From()), p) {
+				return nil, fmt.Errorf("dcel: point equal to node %d", h.From().ID())
+			}
+			if t.isConstrained(h) && orient(point(h.From()), point(h.Twin().From()), p) == 0 {
+				split = h
+				sa, sb = h.From().ID(), h.Twin().From().ID()
+			}
+		}
+	}
+	// crossable returns whether the cavity can extend across the edge of h.
+	crossable := func(h Halfedge) bool {
+		return !t.isConstrained(h) || (split != nil && h.Edge() == split.Edge())
+	}
+
+	// Collect the cavity by a breadth-first search from the located triangle
+	// or hull edge. Hull edges are represented by their boundary halfedges.
+	// The cavity is also recorded in the order of discovery so that the
+	// graph is modified and new IDs are assigned deterministically.
+	var (
+		badFaces = make(map[Face]bool)
+		badHull  = make(map[Halfedge]bool)
+		cavity   []Halfedge
+		queue    []Halfedge
+	)
+	if f != nil {
+		badFaces[f] = true
+		cavity = append(cavity, f.Halfedge())
+		queue = append(queue, f.Halfedge())
+	} else {
+		badHull[ghost] = true
+		cavity = append(cavity, ghost)
+		queue = append(queue, ghost)
+	}
+	// visit tests whether the triangle or hull edge on the left of h is in
+	// conflict with p and if it is, adds it to the cavity.
+	visit := func(h Halfedge) {
+		if f := h.Face(); f != nil {
+			if _, seen := badFaces[f]; seen {
+				return
+			}
+			badFaces[f] = inCircle(point(h.From()), point(h.Next().From()), point(h.Prev().From()), p) > 0
+			if badFaces[f] {
+				cavity = append(cavity, h)
+				queue = append(queue, h)
+			}
+			return
+		}
+		if _, seen := badHull[h]; seen {
+			return
+		}
+		badHull[h] = hullConflict(h, p)
+		if badHull[h] {
+			cavity = append(cavity, h)
+			queue = append(queue, h)
+		}
+	}
+	for len(queue) > 0 {
+		h := queue[0]
+		queue = queue[1:]
+		if h.Face() != nil {
+			for _, fh := range t.g.HalfedgesAround(h.Face()) {
+				if crossable(fh) {
+					visit(fh.Twin())
+				}
+			}
+			continue
+		}
+		if crossable(h) {
+			visit(h.Twin())
+		}
+		visit(h.Next())
+		visit(h.Prev())
+	}
+
+	// Find the boundary of the cavity as halfedges that have the cavity on
+	// their left and the edges inside the cavity.
+	var (
+		boundary [][2]Node
+		interior [][2]int64
+	)
+	inCavity := func(h Halfedge) bool {
+		if h.Face() != nil {
+			return badFaces[h.Face()]
+		}
+		return badHull[h]
+	}
+	for _, h := range cavity {
+		if h.Face() == nil {
+			if !inCavity(h.Twin()) {
+				boundary = append(boundary, [2]Node{h.From(), h.Twin().From()})
+			}
+			continue
+		}
+		for _, h := range t.g.HalfedgesAround(h.Face()) {
+			if !inCavity(h.Twin()) {
+				boundary = append(boundary, [2]Node{h.From(), h.Twin().From()})
+			} else if h.From().ID() < h.Twin().From().ID() || h.Twin().Face() == nil {
+				interior = append(interior, [2]int64{h.From().ID(), h.Twin().From().ID()})
+			}
+		}
+	}
+
+	// Replace the cavity with new triangles.
+	for _, h := range cavity {
+		if f := h.Face(); f != nil {
+			t.g.RemoveFace(f)
+		}
+	}
+	for _, e := range interior {
+		t.g.RemoveEdge(e[0], e[1])
+	}
+	u := addPointNode(t.g, id, p)
+	for _, e := range chain(boundary) {
+		t.addTriangle(e[0], e[1], u)
+	}
+	if split != nil {
+		delete(t.constrained, edgeKey(sa, sb))
+		t.constrained[edgeKey(sa, id)] = true
+		t.constrained[edgeKey(id, sb)] = true
+	}
+	return u, nil
+}
+
+// addTriangle adds a triangle with the given nodes to the triangulation.
+func (t *Triangulation) addTriangle(a, b, c Node) {
+	id := t.g.NewFaceID()
+	if err := t.g.AddFace(id, a, b, c); err != nil {
+		// This would be our bug.
+		panic(err)
+	}
+	t.last = t.g.Face(id)
+}
+
+// locate returns the triangle that contains p or, if p lies outside of the
+// triangulation, a boundary halfedge on the hull that is visible from p.
+func (t *Triangulation) locate(p Point) (Face, Halfedge) {
+	f := t.last
+	if f == nil || t.g.Face(f.ID()) != f {
+		for _, id := range t.g.faceIDs() {
+			f = t.g.faces[id]
+			break
+		}
+	}
+
+	// Walk from f towards p across edges that separate the current triangle
+	// from p. The walk terminates in Delaunay triangulations but the number of
+	// steps is bounded nevertheless and linear search is used as a fallback.
+	maxSteps := 4*len(t.g.faces) + 16
+walk:
+	for step := 0; step < maxSteps; step++ {
+		t.steps++
+		h := f.Halfedge()
+		for i := t.steps % 3; i > 0; i-- {
+			h = h.Next()
+		}
+		for i := 0; i < 3; i++ {
+			if orient(point(h.From()), point(h.Next().From()), p) < 0 {
+				if h.Twin().Face() == nil {
+					return nil, h.Twin()
+				}
+				f = h.Twin().Face()
+				continue walk
+			}
+			h = h.Next()
+		}
+		return f, nil
+	}
+
+	for _, id := range t.g.faceIDs() {
+		if f := t.g.faces[id]; t.contains(f, p) {
+			return f, nil
+		}
+	}
+	for _, h := range t.g.Loop(t.g.BoundaryLoops()[0]) {
+		if orient(point(h.From()), point(h.Twin().From()), p) > 0 {
+			return nil, h
+		}
+	}
+	panic("dcel: point location failed")
+}
+
+// contains returns whether the triangle f contains p, including its boundary.
+func (t *Triangulation) contains(f Face, p Point) bool {
+	for _, h := range t.g.HalfedgesAround(f) {
+		if orient(point(h.From()), point(h.Twin().From()), p) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// hullConflict returns whether the boundary halfedge h on the convex hull is
+// visible from p, that is whether p lies strictly outside of the hull edge or
+// in the interior of the edge.
+func hullConflict(h Halfedge, p Point) bool {
+	a, b := point(h.From()), point(h.Twin().From())
+	o := orient(a, b, p)
+	if o != 0 {
+		return o > 0
+	}
+	return p.Sub(a).Dot(b.Sub(a)) > 0 && p.Sub(b).Dot(a.Sub(b)) > 0
+}
+
+// chain orders the edges given by pairs of nodes so that each edge starts at
+// the node where the previous edge ends. The edges must form a single path or
+// a single cycle.
+func chain(edges [][2]Node) [][2]Node {
+	from := make(map[int64]int)
+	to := make(map[int64]bool)
+	for i, e := range edges {
+		from[e[0].ID()] = i
+		to[e[1].ID()] = true
+	}
+	start := 0
+	for i, e := range edges {
+		if !to[e[0].ID()] {
+			start = i
+			break
+		}
+	}
+	ordered := make([][2]Node, 0, len(edges))
+	for i, ok := start, true; ok && len(ordered) < len(edges); i, ok = from[edges[i][1].ID()] {
+		ordered = append(ordered, edges[i])
+	}
+	return ordered
+}
+
+// point returns the position of u which must implement PointNode.
+func point(u Node) Point {
+	return u.(PointNode).Point()
+}
+
+// samePosition returns whether p and q have the same X and Y coordinates.
+func samePosition(p, q Point) bool {
+	return p.X == q.X && p.Y == q.Y
+}
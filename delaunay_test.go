@@ -0,0 +1,144 @@
+package dcel
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"testing"
+)
+
+// checkDelaunay checks that the faces of t are counterclockwise triangles that
+// satisfy the empty circumcircle property across unconstrained edges and that
+// the number of faces is consistent with the number of nodes and hull nodes.
+func checkDelaunay(t *testing.T, tri *Triangulation) {
+	t.Helper()
+	g := tri.Graph()
+	for _, f := range g.Faces() {
+		hedges := g.HalfedgesAround(f)
+		if len(hedges) != 3 {
+			t.Fatalf("dcel: face %d is not a triangle", f.ID())
+		}
+		a, b, c := point(hedges[0].From()), point(hedges[1].From()), point(hedges[2].From())
+		if orient(a, b, c) <= 0 {
+			t.Errorf("dcel: face %d is not counterclockwise", f.ID())
+		}
+		for _, h := range hedges {
+			if h.Twin().Face() == nil || tri.IsConstrained(h.Edge()) {
+				continue
+			}
+			d := point(h.Twin().Prev().From())
+			if inCircle(a, b, c, d) > 0 {
+				t.Errorf("dcel: face %d is not Delaunay", f.ID())
+			}
+		}
+	}
+	n := g.Nodes().Len()
+	hull := tri.Hull()
+	if want := 2*n - len(hull) - 2; len(g.Faces()) != want {
+		t.Errorf("dcel: wrong number of triangles: got %d, want %d", len(g.Faces()), want)
+	}
+	for i := range hull {
+		a, b, c := point(hull[i]), point(hull[(i+1)%len(hull)]), point(hull[(i+2)%len(hull)])
+		if orient(a, b, c) < 0 {
+			t.Error("dcel: hull is not convex")
+		}
+	}
+}
+
+func TestDelaunayRandom(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(1, 1))
+	for _, n := range []int{3, 4, 10, 100, 1000} {
+		points := make([]Point, n)
+		for i := range points {
+			points[i] = Point{X: rnd.Float64(), Y: rnd.Float64()}
+		}
+		tri, err := NewDelaunay(nil, points)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tri.Graph().Nodes().Len() != n {
+			t.Errorf("dcel: wrong number of nodes: %d", tri.Graph().Nodes().Len())
+		}
+		checkDelaunay(t, tri)
+	}
+}
+
+func TestDelaunayDeterministic(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(1, 1))
+	points := make([]Point, 200)
+	for i := range points {
+		points[i] = Point{X: rnd.Float64(), Y: rnd.Float64()}
+	}
+	// layout returns the nodes of the faces and edges in the order of their
+	// IDs.
+	layout := func() string {
+		tri, err := NewDelaunay(nil, points)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Extend the cavity also over the hull.
+		if _, err := tri.Insert(Point{X: 2, Y: 0.5}); err != nil {
+			t.Fatal(err)
+		}
+		g := tri.Graph()
+		var b strings.Builder
+		for _, id := range g.faceIDs() {
+			fmt.Fprintf(&b, "f%d:", id)
+			for _, h := range g.HalfedgesAround(g.faces[id]) {
+				fmt.Fprintf(&b, " %d", h.From().ID())
+			}
+			b.WriteByte('\n')
+		}
+		for _, id := range g.edgeIDs() {
+			e := g.edges[id]
+			fmt.Fprintf(&b, "e%d: %d %d\n", id, e.From().ID(), e.To().ID())
+		}
+		return b.String()
+	}
+	want := layout()
+	for i := 0; i < 10; i++ {
+		if got := layout(); got != want {
+			t.Fatal("dcel: triangulation of the same points differs between runs")
+		}
+	}
+}
+
+func TestDelaunayGrid(t *testing.T) {
+	// Cocircular and collinear points.
+	var points []Point
+	for i := 0; i < 10; i++ {
+		for j := 0; j < 10; j++ {
+			points = append(points, Point{X: float64(i), Y: float64(j)})
+		}
+	}
+	tri, err := NewDelaunay(nil, points)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkDelaunay(t, tri)
+	if len(tri.Hull()) != 36 {
+		t.Errorf("dcel: wrong number of hull nodes: %d", len(tri.Hull()))
+	}
+
+	u, err := tri.Insert(Point{X: 4.5, Y: 4.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.ID() != 100 {
+		t.Errorf("dcel: wrong ID of inserted node: %d", u.ID())
+	}
+	if _, err = tri.Insert(Point{X: -1, Y: 4}); err != nil {
+		t.Fatal(err)
+	}
+	checkDelaunay(t, tri)
+	if _, err = tri.Insert(Point{X: 3, Y: 3}); err == nil {
+		t.Error("dcel: expected error when inserting duplicate point")
+	}
+}
+
+func TestDelaunayCollinear(t *testing.T) {
+	_, err := NewDelaunay(nil, []Point{{X: 0}, {X: 1}, {X: 2}, {X: 3}})
+	if err == nil {
+		t.Error("dcel: expected error for collinear points")
+	}
+}
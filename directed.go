@@ -0,0 +1,129 @@
+package dcel
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/graph/iterator"
+)
+
+var (
+	directedView Directed
+	_            graph.Directed         = directedView
+	_            graph.WeightedDirected = directedView
+)
+
+// Directed is a directed view of a Graph. Each halfedge of the graph is a
+// directed edge from its From node to the From node of its Twin. Since every
+// edge of the graph consists of two halfedges, the view contains the directed
+// edges in both directions.
+type Directed struct {
+	g *Graph
+}
+
+// Directed returns a directed view of the graph.
+func (g *Graph) Directed() Directed {
+	return Directed{g: g}
+}
+
+// Node returns the node with the given id or nil if it does not exist within
+// the graph.
+func (d Directed) Node(id int64) graph.Node { return d.g.Node(id) }
+
+// Nodes returns all the nodes in the graph.
+func (d Directed) Nodes() graph.Nodes { return d.g.Nodes() }
+
+// From returns all nodes that can be reached by a halfedge from the node with
+// the given id. The nodes are returned in the order of the halfedges around the
+// node.
+func (d Directed) From(id int64) graph.Nodes {
+	hedges := d.g.HalfedgesFrom(id)
+	if len(hedges) == 0 {
+		return graph.Empty
+	}
+	nodes := make([]graph.Node, len(hedges))
+	for i, h := range hedges {
+		nodes[i] = h.Twin().From()
+	}
+	return iterator.NewOrderedNodes(nodes)
+}
+
+// To returns all nodes that can reach the node with the given id by a
+// halfedge. The nodes are returned in the order of the halfedges around the
+// node.
+func (d Directed) To(id int64) graph.Nodes {
+	hedges := d.g.HalfedgesTo(id)
+	if len(hedges) == 0 {
+		return graph.Empty
+	}
+	nodes := make([]graph.Node, len(hedges))
+	for i, h := range hedges {
+		nodes[i] = h.From()
+	}
+	return iterator.NewOrderedNodes(nodes)
+}
+
+// HasEdgeBetween returns whether an edge exists between nodes with IDs xid and
+// yid.
+func (d Directed) HasEdgeBetween(xid, yid int64) bool {
+	return d.g.HasEdgeBetween(xid, yid)
+}
+
+// HasEdgeFromTo returns whether a halfedge exists from the node with ID uid to
+// the node with ID vid.
+func (d Directed) HasEdgeFromTo(uid, vid int64) bool {
+	return d.g.Halfedge(uid, vid) != nil
+}
+
+// Edge returns the halfedge from the node with ID uid to the node with ID vid
+// as a DirectedEdge or nil if the nodes are not connected.
+func (d Directed) Edge(uid, vid int64) graph.Edge {
+	return d.WeightedEdge(uid, vid)
+}
+
+// WeightedEdge returns the halfedge from the node with ID uid to the node with
+// ID vid as a DirectedEdge or nil if the nodes are not connected. The weight of
+// the returned edge is the weight of the undirected edge that contains the
+// halfedge.
+func (d Directed) WeightedEdge(uid, vid int64) graph.WeightedEdge {
+	h := d.g.Halfedge(uid, vid)
+	if h == nil {
+		return nil
+	}
+	return DirectedEdge{h: h, w: d.g.edgeWeight(h.Edge())}
+}
+
+// Weight returns the weight of the halfedge from the node with ID xid to the
+// node with ID yid. If xid == yid, Weight returns 0 and true. If the nodes are
+// not connected, Weight returns +Inf and false.
+func (d Directed) Weight(xid, yid int64) (w float64, ok bool) {
+	if xid == yid {
+		return 0, true
+	}
+	h := d.g.Halfedge(xid, yid)
+	if h == nil {
+		return math.Inf(1), false
+	}
+	return d.g.edgeWeight(h.Edge()), true
+}
+
+// DirectedEdge is a directed edge in the directed view of a Graph.
+type DirectedEdge struct {
+	h Halfedge
+	w float64
+}
+
+// Halfedge returns the halfedge that forms the edge.
+func (e DirectedEdge) Halfedge() Halfedge { return e.h }
+
+// From returns the From node of the halfedge.
+func (e DirectedEdge) From() graph.Node { return e.h.From() }
+
+// To returns the From node of the Twin of the halfedge.
+func (e DirectedEdge) To() graph.Node { return e.h.Twin().From() }
+
+// ReversedEdge returns the edge formed by the Twin of the halfedge.
+func (e DirectedEdge) ReversedEdge() graph.Edge { return DirectedEdge{h: e.h.Twin(), w: e.w} }
+
+// Weight returns the weight of the edge.
+func (e DirectedEdge) Weight() float64 { return e.w }
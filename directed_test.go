@@ -0,0 +1,45 @@
+package dcel
+
+import (
+	"testing"
+
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/graph/topo"
+)
+
+func TestDirected(t *testing.T) {
+	g := newTetrahedron()
+	d := g.Directed()
+
+	for _, u := range graph.NodesOf(g.Nodes()) {
+		from := graph.NodesOf(d.From(u.ID()))
+		hedges := g.HalfedgesFrom(u.ID())
+		if len(from) != len(hedges) {
+			t.Fatalf("dcel: wrong number of nodes from %d", u.ID())
+		}
+		for i, h := range hedges {
+			if from[i] != h.Twin().From() {
+				t.Errorf("dcel: node %d from %d not in rotation order", from[i].ID(), u.ID())
+			}
+		}
+		if d.To(u.ID()).Len() != len(hedges) {
+			t.Errorf("dcel: wrong number of nodes to %d", u.ID())
+		}
+	}
+
+	e := d.Edge(0, 1).(DirectedEdge)
+	if e.Halfedge() != g.Halfedge(0, 1) {
+		t.Error("dcel: wrong halfedge of directed edge")
+	}
+	r := e.ReversedEdge()
+	if r.From().ID() != 1 || r.To().ID() != 0 {
+		t.Error("dcel: wrong reversed directed edge")
+	}
+	if d.Edge(0, 0) != nil {
+		t.Error("dcel: unexpected directed loop edge")
+	}
+
+	if scc := topo.TarjanSCC(d); len(scc) != 1 {
+		t.Errorf("dcel: wrong number of strongly connected components: %d", len(scc))
+	}
+}
@@ -0,0 +1,110 @@
+package dcel
+
+import "gonum.org/v1/gonum/graph"
+
+// Dual returns the dual of the graph and a map from the IDs of edges in g to
+// the IDs of their dual edges. If items is nil, Base will be used.
+//
+// The map holds only edges because the dual reuses the other IDs as they are:
+// the dual node of a face has the ID of the face and the dual face of a node
+// has the ID of the node.
+//
+// An error is returned if a dual face cannot be added because the rings of
+// faces around two nodes of g pass through two faces in the same direction and
+// would use the same dual halfedge, or if a dual edge cannot be attached to a
+// dual node without a free halfedge. These conditions depend on how the rings
+// of several nodes fit together and are only found while the dual is built,
+// so they are reported as an error and no partial dual is returned.
+//
+// Each face of g becomes a node of the dual with the ID of the face. If the
+// dual node carries a position and the nodes of the face carry a position, it
+// is placed at their centroid. Each edge of g with two adjacent faces becomes
+// an edge of the dual between the nodes of its faces. Each node of g with a
+// closed ring of adjacent faces becomes a face of the dual with the ID of the
+// node. The faces of the dual are oriented in the same way as the faces of g.
+//
+// Nodes of g whose ring of faces has less than three faces or passes more than
+// once through a face have no dual face. Edges of g whose dual edge would
+// duplicate an already existing edge of the dual are mapped to that edge.
+func (g *Graph) Dual(items Items) (*Graph, map[int64]int64, error) {
+	d := New(items)
+	for _, id := range g.faceIDs() {
+		u := d.AddNode(id)
+		pu, ok := u.(PointNode)
+		if !ok {
+			continue
+		}
+		var nodes []Node
+		for _, h := range g.HalfedgesAround(g.faces[id]) {
+			nodes = append(nodes, h.From())
+		}
+		if pts, ok := positions(nodes); ok {
+			pu.SetPoint(centroid(pts))
+		}
+	}
+
+	// Add the dual faces first so that their edges are attached in the order
+	// given by the rotation around the primal nodes.
+	for _, id := range g.nodeIDs() {
+		faces, ok := ring(g.nodes[id])
+		if !ok || len(faces) < 3 || hasRepeated(faces) {
+			continue
+		}
+		if err := d.AddFace(id, faces...); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	dual := make(map[int64]int64)
+	for _, id := range g.edgeIDs() {
+		h1, h2 := g.edges[id].Halfedges()
+		f1, f2 := h1.Face(), h2.Face()
+		if f1 == nil || f2 == nil {
+			continue
+		}
+		h, err := d.addEdge(d.nodes[f1.ID()], d.nodes[f2.ID()])
+		if err != nil {
+			return nil, nil, err
+		}
+		dual[id] = h.Edge().ID()
+	}
+	return d, dual, nil
+}
+
+// ring returns the faces around u in counterclockwise order. If any of the
+// halfedges from u has no adjacent face, ring returns false.
+func ring(u Node) ([]graph.Node, bool) {
+	if u.Halfedge() == nil {
+		return nil, false
+	}
+	var faces []graph.Node
+	for h := u.Halfedge(); ; {
+		f := h.Face()
+		if f == nil {
+			return nil, false
+		}
+		faces = append(faces, nodeID(f.ID()))
+		h = h.Prev().Twin()
+		if h == u.Halfedge() {
+			break
+		}
+	}
+	return faces, true
+}
+
+// hasRepeated returns whether a node ID occurs more than once in nodes.
+func hasRepeated(nodes []graph.Node) bool {
+	seen := make(map[int64]bool, len(nodes))
+	for _, u := range nodes {
+		if seen[u.ID()] {
+			return true
+		}
+		seen[u.ID()] = true
+	}
+	return false
+}
+
+// nodeID is a graph.Node that is only an identifier.
+type nodeID int64
+
+func (n nodeID) ID() int64 { return int64(n) }
@@ -0,0 +1,70 @@
+package dcel
+
+import "testing"
+
+func TestDual(t *testing.T) {
+	for _, test := range []struct {
+		name                string
+		g                   *Graph
+		nodes, edges, faces int
+	}{
+		{"tetrahedron", newTetrahedron(), 4, 6, 4},
+		{"square", newSquare(nil), 2, 1, 0},
+		{"torus", newTorus(3, 4), 12, 24, 12},
+	} {
+		d, dual, err := test.g.Dual(nil)
+		if err != nil {
+			t.Fatalf("dcel: %s: %v", test.name, err)
+		}
+		top := d.Topology()
+		if top.Nodes != test.nodes || top.Edges != test.edges || top.Faces != test.faces {
+			t.Errorf("dcel: %s: wrong dual: got %d nodes, %d edges, %d faces, want %d, %d, %d",
+				test.name, top.Nodes, top.Edges, top.Faces, test.nodes, test.edges, test.faces)
+		}
+		if len(dual) != test.edges {
+			t.Errorf("dcel: %s: wrong number of mapped edges: %d", test.name, len(dual))
+		}
+		for _, f := range test.g.Faces() {
+			if d.Node(f.ID()) == nil {
+				t.Errorf("dcel: %s: face %d has no dual node", test.name, f.ID())
+			}
+		}
+		// The dual face of a node with a closed ring has the ID of the
+		// node and its nodes are the faces around it.
+		for _, df := range d.Faces() {
+			for _, h := range d.HalfedgesAround(df) {
+				if f := test.g.Face(h.From().ID()); f == nil || !hasNode(test.g, f, df.ID()) {
+					t.Errorf("dcel: %s: dual face %d does not match node %d", test.name, df.ID(), df.ID())
+				}
+			}
+		}
+		for id, did := range dual {
+			h1, h2 := test.g.edges[id].Halfedges()
+			dh := d.Halfedge(h1.Face().ID(), h2.Face().ID())
+			if dh == nil || dh.Edge().ID() != did {
+				t.Errorf("dcel: %s: edge %d wrongly mapped to %d", test.name, id, did)
+			}
+		}
+	}
+}
+
+func TestDualPosition(t *testing.T) {
+	d, _, err := newSquare(PointBase{}).Dual(PointBase{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Point{2.0 / 3, 1.0 / 3, 0}
+	if got := d.Node(0).(PointNode).Point(); got != want {
+		t.Errorf("dcel: wrong position of dual node: got %v, want %v", got, want)
+	}
+}
+
+// hasNode returns whether the face f of g has the node with the given id.
+func hasNode(g *Graph, f Face, id int64) bool {
+	for _, h := range g.HalfedgesAround(f) {
+		if h.From().ID() == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package dcel
+
+import "math"
+
+// Point is a point or a vector in three-dimensional space. Planar algorithms
+// use only the X and Y coordinates.
+type Point struct {
+	X, Y, Z float64
+}
+
+// Add returns the vector sum of p and q.
+func (p Point) Add(q Point) Point { return Point{p.X + q.X, p.Y + q.Y, p.Z + q.Z} }
+
+// Sub returns the vector difference of p and q.
+func (p Point) Sub(q Point) Point { return Point{p.X - q.X, p.Y - q.Y, p.Z - q.Z} }
+
+// Scale returns the vector p scaled by f.
+func (p Point) Scale(f float64) Point { return Point{f * p.X, f * p.Y, f * p.Z} }
+
+// Dot returns the dot product of p and q.
+func (p Point) Dot(q Point) float64 { return p.X*q.X + p.Y*q.Y + p.Z*q.Z }
+
+// Cross returns the cross product of p and q.
+func (p Point) Cross(q Point) Point {
+	return Point{
+		p.Y*q.Z - p.Z*q.Y,
+		p.Z*q.X - p.X*q.Z,
+		p.X*q.Y - p.Y*q.X,
+	}
+}
+
+// Norm returns the Euclidean norm of p.
+func (p Point) Norm() float64 { return math.Sqrt(p.Dot(p)) }
+
+// Box is an axis-aligned rectangle in the XY plane.
+type Box struct {
+	Min, Max Point
+}
+
+// Empty returns whether the box has zero area.
+func (b Box) Empty() bool { return !(b.Min.X < b.Max.X && b.Min.Y < b.Max.Y) }
+
+// Contains returns whether the X and Y coordinates of p lie in the box,
+// including its boundary.
+func (b Box) Contains(p Point) bool {
+	return b.Min.X <= p.X && p.X <= b.Max.X && b.Min.Y <= p.Y && p.Y <= b.Max.Y
+}
+
+// EdgeLength is a WeightFunc that returns the Euclidean distance between the
+// end nodes of e. If any of the nodes does not carry a position, EdgeLength
+// returns e.Weight().
+func EdgeLength(e Edge) float64 {
+	h1, h2 := e.Halfedges()
+	p, ok1 := position(h1.From())
+	q, ok2 := position(h2.From())
+	if !ok1 || !ok2 {
+		return e.Weight()
+	}
+	return dist(p, q)
+}
+
+// dist returns the Euclidean distance between p and q.
+func dist(p, q Point) float64 { return p.Sub(q).Norm() }
+
+// addPointNode adds a node with the given id to g and sets its position to p.
+// It panics if the node allocated by g does not implement PointNode.
+func addPointNode(g *Graph, id int64, p Point) Node {
+	u := g.AddNode(id)
+	pu, ok := u.(PointNode)
+	if !ok {
+		panic("dcel: nodes do not implement PointNode")
+	}
+	pu.SetPoint(p)
+	return u
+}
+
+// position returns the position of u and whether u carries one.
+func position(u Node) (Point, bool) {
+	if p, ok := u.(PointNode); ok {
+		return p.Point(), true
+	}
+	return Point{}, false
+}
+
+// positions returns the positions of the given nodes or false if at least one
+// of them does not carry a position.
+func positions(nodes []Node) ([]Point, bool) {
+	pts := make([]Point, len(nodes))
+	for i, u := range nodes {
+		p, ok := position(u)
+		if !ok {
+			return nil, false
+		}
+		pts[i] = p
+	}
+	return pts, true
+}
+
+// centroid returns the arithmetic mean of pts.
+func centroid(pts []Point) Point {
+	var c Point
+	for _, p := range pts {
+		c = c.Add(p)
+	}
+	return c.Scale(1 / float64(len(pts)))
+}
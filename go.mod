@@ -0,0 +1,5 @@
+module github.com/vladimir-ch/dcel
+
+go 1.23.0
+
+require gonum.org/v1/gonum v0.16.0
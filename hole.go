@@ -0,0 +1,196 @@
+package dcel
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/graph"
+)
+
+// FillStrategy specifies how FillHole closes a boundary loop.
+type FillStrategy int
+
+const (
+	// FillPolygon closes the boundary loop with a single face.
+	FillPolygon FillStrategy = iota
+	// FillFan closes the boundary loop with a fan of triangles around a new
+	// node. If the nodes of the loop carry a position, the new node is placed
+	// at their centroid.
+	FillFan
+	// FillMinimumWeight closes the boundary loop with triangles that minimize
+	// the total length of their edges. It requires nodes with a position.
+	FillMinimumWeight
+)
+
+// FillHole closes the boundary loop that contains the halfedge start with new
+// faces according to strategy and returns the new faces. The faces are
+// allocated with IDs given by NewFaceID.
+//
+// If start has an adjacent face, if the loop has less than three halfedges or
+// if it passes more than once through a node, an error is returned and the
+// graph is not modified.
+func (g *Graph) FillHole(start Halfedge, strategy FillStrategy) ([]Face, error) {
+	if start.Face() != nil {
+		return nil, errors.New("dcel: cannot fill hole, halfedge is not on a boundary")
+	}
+	loop := g.Loop(start)
+	if loop == nil {
+		return nil, errors.New("dcel: cannot fill hole, halfedge does not belong to the graph")
+	}
+	if len(loop) < 3 {
+		return nil, fmt.Errorf("dcel: cannot fill hole with only %d halfedges", len(loop))
+	}
+	nodes := make([]Node, len(loop))
+	seen := make(map[int64]struct{})
+	for i, h := range loop {
+		u := h.From()
+		if _, ok := seen[u.ID()]; ok {
+			return nil, fmt.Errorf("dcel: cannot fill hole, boundary loop passes twice through node %d", u.ID())
+		}
+		seen[u.ID()] = struct{}{}
+		nodes[i] = u
+	}
+
+	switch strategy {
+	case FillPolygon:
+		return g.fillPolygon(nodes)
+	case FillFan:
+		return g.fillFan(nodes)
+	case FillMinimumWeight:
+		return g.fillMinimumWeight(nodes)
+	default:
+		panic(fmt.Sprintf("dcel: unknown fill strategy %d", strategy))
+	}
+}
+
+func (g *Graph) fillPolygon(nodes []Node) ([]Face, error) {
+	id := g.NewFaceID()
+	// The face uses the free halfedges of the boundary loop, so AddFace adds
+	// no edges even if it fails.
+	if err := g.AddFace(id, toGraphNodes(nodes)...); err != nil {
+		return nil, err
+	}
+	return []Face{g.Face(id)}, nil
+}
+
+func (g *Graph) fillFan(nodes []Node) ([]Face, error) {
+	c := g.AddNode(g.NewNodeID())
+	if pc, ok := c.(PointNode); ok {
+		if pts, ok := positions(nodes); ok {
+			pc.SetPoint(centroid(pts))
+		}
+	}
+
+	var faces []Face
+	for i, u := range nodes {
+		v := nodes[(i+1)%len(nodes)]
+		id := g.NewFaceID()
+		if err := g.AddFace(id, u, v, c); err != nil {
+			// Removing the center node removes also the faces added so
+			// far and the edges to it, including any added by the failed
+			// AddFace.
+			g.RemoveNode(c.ID())
+			return nil, err
+		}
+		faces = append(faces, g.Face(id))
+	}
+	return faces, nil
+}
+
+func (g *Graph) fillMinimumWeight(nodes []Node) ([]Face, error) {
+	pts, ok := positions(nodes)
+	if !ok {
+		return nil, errors.New("dcel: minimum weight hole filling requires nodes with a position")
+	}
+
+	// Compute the minimum weight triangulation of the polygon by dynamic
+	// programming. weight[i][j] is the minimum weight of a triangulation of
+	// the polygon nodes[i], ..., nodes[j] and split[i][j] is the index of the
+	// third node of the triangle on the edge between nodes[i] and nodes[j].
+	n := len(nodes)
+	weight := make([][]float64, n)
+	split := make([][]int, n)
+	for i := range weight {
+		weight[i] = make([]float64, n)
+		split[i] = make([]int, n)
+	}
+	// diagonal returns whether a new edge can be added between nodes[i] and
+	// nodes[j]. Diagonals that already exist as edges elsewhere in the graph
+	// would have to be shared with faces outside of the hole.
+	diagonal := func(i, j int) bool {
+		if j-i == 1 || (i == 0 && j == n-1) {
+			return true
+		}
+		return g.Halfedge(nodes[i].ID(), nodes[j].ID()) == nil
+	}
+	for d := 2; d < n; d++ {
+		for i := 0; i+d < n; i++ {
+			j := i + d
+			weight[i][j] = math.Inf(1)
+			if !diagonal(i, j) {
+				continue
+			}
+			for k := i + 1; k < j; k++ {
+				w := weight[i][k] + weight[k][j] + dist(pts[i], pts[k]) + dist(pts[k], pts[j]) + dist(pts[j], pts[i])
+				if w < weight[i][j] {
+					weight[i][j] = w
+					split[i][j] = k
+				}
+			}
+		}
+	}
+	if math.IsInf(weight[0][n-1], 1) {
+		return nil, errors.New("dcel: cannot fill hole, no triangulation avoids existing edges")
+	}
+
+	// Collect the triangles starting from the one on the edge from
+	// nodes[n-1] to nodes[0] so that each triangle shares an edge with a
+	// triangle added before it.
+	var (
+		tris  [][3]int
+		stack = [][2]int{{0, n - 1}}
+	)
+	for len(stack) > 0 {
+		ij := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		i, j := ij[0], ij[1]
+		if j-i < 2 {
+			continue
+		}
+		k := split[i][j]
+		tris = append(tris, [3]int{i, k, j})
+		stack = append(stack, [2]int{i, k}, [2]int{k, j})
+	}
+
+	var faces []Face
+	for _, t := range tris {
+		id := g.NewFaceID()
+		if err := g.AddFace(id, nodes[t[0]], nodes[t[1]], nodes[t[2]]); err != nil {
+			// Remove the faces added so far and the diagonals, including
+			// any added by the failed AddFace. The diagonals did not exist
+			// before.
+			for _, f := range faces {
+				g.RemoveFace(f)
+			}
+			for _, t := range tris {
+				for _, d := range [2][2]int{{t[0], t[1]}, {t[1], t[2]}} {
+					if d[1]-d[0] > 1 {
+						g.RemoveEdge(nodes[d[0]].ID(), nodes[d[1]].ID())
+					}
+				}
+			}
+			return nil, err
+		}
+		faces = append(faces, g.Face(id))
+	}
+	return faces, nil
+}
+
+func toGraphNodes(nodes []Node) []graph.Node {
+	gn := make([]graph.Node, len(nodes))
+	for i, u := range nodes {
+		gn[i] = u
+	}
+	return gn
+}
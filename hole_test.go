@@ -0,0 +1,75 @@
+package dcel
+
+import "testing"
+
+// newSquare returns a graph with a unit square split into two triangles along
+// the diagonal from node 0 to node 2.
+func newSquare(items Items) *Graph {
+	g := New(items)
+	for i, p := range []Point{{0, 0, 0}, {1, 0, 0}, {1, 1, 0}, {0, 1, 0}} {
+		u := g.AddNode(int64(i))
+		if pu, ok := u.(PointNode); ok {
+			pu.SetPoint(p)
+		}
+	}
+	if err := g.AddFace(0, NodeID(0), NodeID(1), NodeID(2)); err != nil {
+		panic(err)
+	}
+	if err := g.AddFace(1, NodeID(0), NodeID(2), NodeID(3)); err != nil {
+		panic(err)
+	}
+	return g
+}
+
+func TestFillHole(t *testing.T) {
+	for _, test := range []struct {
+		strategy FillStrategy
+		items    Items
+		faces    int
+		nodes    int
+	}{
+		{FillPolygon, nil, 1, 4},
+		{FillFan, nil, 4, 5},
+		{FillFan, PointBase{}, 4, 5},
+		{FillMinimumWeight, PointBase{}, 2, 4},
+	} {
+		g := newSquare(test.items)
+		loops := g.BoundaryLoops()
+		if len(loops) != 1 {
+			t.Fatalf("dcel: wrong number of boundary loops: %d", len(loops))
+		}
+
+		faces, err := g.FillHole(loops[0], test.strategy)
+		if err != nil {
+			t.Errorf("dcel: strategy %d: %v", test.strategy, err)
+			continue
+		}
+		if len(faces) != test.faces {
+			t.Errorf("dcel: strategy %d: wrong number of new faces: got %d, want %d",
+				test.strategy, len(faces), test.faces)
+		}
+		if len(g.Faces()) != test.faces+2 {
+			t.Errorf("dcel: strategy %d: wrong number of faces", test.strategy)
+		}
+		if g.Nodes().Len() != test.nodes {
+			t.Errorf("dcel: strategy %d: wrong number of nodes", test.strategy)
+		}
+		if len(g.BoundaryLoops()) != 0 {
+			t.Errorf("dcel: strategy %d: hole not closed", test.strategy)
+		}
+		if test.strategy == FillMinimumWeight && g.Halfedge(1, 3) == nil {
+			t.Error("dcel: minimum weight filling did not use the free diagonal")
+		}
+	}
+}
+
+func TestFillHoleNoPositions(t *testing.T) {
+	g := newSquare(nil)
+	_, err := g.FillHole(g.BoundaryLoops()[0], FillMinimumWeight)
+	if err == nil {
+		t.Error("dcel: expected error when filling without positions")
+	}
+	if len(g.Faces()) != 2 || len(g.BoundaryLoops()) != 1 {
+		t.Error("dcel: graph modified by failed hole filling")
+	}
+}
@@ -0,0 +1,169 @@
+package dcel
+
+import (
+	"fmt"
+	"sort"
+
+	"gonum.org/v1/gonum/graph"
+)
+
+// NonManifoldPolicy specifies how Import resolves edges shared by more than
+// two faces.
+type NonManifoldPolicy int
+
+const (
+	// ReportNonManifold makes Import return an error without modifying the
+	// graph if any edge is shared by more than two faces.
+	ReportNonManifold NonManifoldPolicy = iota
+	// DropFaces keeps the first face with the edge and the first following
+	// face that traverses the edge in the opposite direction. The other faces
+	// with the edge are not added.
+	DropFaces
+	// SeparateSheets pairs the faces with the edge in their order into sheets
+	// of two faces that traverse the edge in opposite directions. The end
+	// nodes of the edge are duplicated for each sheet after the first so that
+	// every sheet has its own copy of the edge.
+	SeparateSheets
+)
+
+// ImportOptions are the options of Import.
+type ImportOptions struct {
+	// NonManifold is the policy for edges shared by more than two faces.
+	NonManifold NonManifoldPolicy
+	// SeparateFans specifies whether nodes whose faces form more than one fan
+	// are duplicated as by SeparateFans. Fans are always separated with the
+	// SeparateSheets policy.
+	SeparateFans bool
+}
+
+// ImportResult describes the faces added by Import.
+type ImportResult struct {
+	// FaceIDs holds the IDs of the added faces in the order of the imported
+	// faces. Faces that were not added have ID -1.
+	FaceIDs []int64
+	// NonManifoldEdges holds the IDs of the end nodes of the edges shared by
+	// more than two faces in increasing order.
+	NonManifoldEdges [][2]int64
+	// Dropped holds the increasing indices of the faces that were not added.
+	Dropped []int
+	// Duplicates holds the IDs of the nodes added for each duplicated node.
+	Duplicates map[int64][]int64
+}
+
+// Import adds faces given by their nodes to the graph. Unlike AddFace, Import
+// accepts edges shared by more than two faces and resolves them with the
+// policy given by opts. The faces are added in their order with IDs returned
+// by NewFaceID. Nodes added as duplicates get IDs returned by NewNodeID and
+// the position and node property values of the original node. The faces
+// slice is not modified.
+//
+// If a face cannot be added, Import returns the error and the faces added
+// before it remain in the graph. Import panics if a face has fewer than 3
+// nodes.
+func (g *Graph) Import(faces [][]graph.Node, opts ImportOptions) (ImportResult, error) {
+	faces = append([][]graph.Node(nil), faces...)
+	for i, f := range faces {
+		faces[i] = append([]graph.Node(nil), f...)
+	}
+
+	var res ImportResult
+	edges, offset := faceEdges(faces)
+	for key, sides := range edges {
+		if len(sides) > 2 {
+			res.NonManifoldEdges = append(res.NonManifoldEdges, key)
+		}
+	}
+	sort.Slice(res.NonManifoldEdges, func(i, j int) bool {
+		a, b := res.NonManifoldEdges[i], res.NonManifoldEdges[j]
+		return a[0] < b[0] || a[0] == b[0] && a[1] < b[1]
+	})
+	if opts.NonManifold == ReportNonManifold && len(res.NonManifoldEdges) > 0 {
+		return res, fmt.Errorf("dcel: %d edges shared by more than two faces", len(res.NonManifoldEdges))
+	}
+
+	for _, f := range faces {
+		for _, u := range f {
+			if !g.has(u.ID()) {
+				g.AddNode(u.ID())
+			}
+		}
+	}
+	newNode := func(u graph.Node) graph.Node {
+		v := g.AddNode(g.NewNodeID())
+		if p, ok := position(g.nodes[u.ID()]); ok {
+			setPoint(v, p)
+		}
+		g.props.copyNode(u.ID(), v.ID())
+		return v
+	}
+
+	dropped := make([]bool, len(faces))
+	switch opts.NonManifold {
+	case ReportNonManifold:
+	case DropFaces:
+		for _, key := range res.NonManifoldEdges {
+			var keep []edgeSide
+			for _, s := range edges[key] {
+				switch {
+				case dropped[s.face]:
+				case len(keep) == 0, len(keep) == 1 && keep[0].forward != s.forward:
+					keep = append(keep, s)
+				default:
+					dropped[s.face] = true
+					res.Dropped = append(res.Dropped, s.face)
+				}
+			}
+		}
+		sort.Ints(res.Dropped)
+	case SeparateSheets:
+		var pairs [][2]edgeSide
+		for _, sides := range edges {
+			paired := make([]bool, len(sides))
+			for i, s := range sides {
+				if paired[i] {
+					continue
+				}
+				for j := i + 1; j < len(sides); j++ {
+					if !paired[j] && sides[j].forward != s.forward {
+						paired[j] = true
+						pairs = append(pairs, [2]edgeSide{s, sides[j]})
+						break
+					}
+				}
+			}
+		}
+		res.Duplicates = separateFans(faces, offset, pairs, newNode)
+	default:
+		panic(fmt.Sprintf("dcel: unknown non-manifold policy %d", opts.NonManifold))
+	}
+	if opts.SeparateFans && opts.NonManifold != SeparateSheets {
+		res.Duplicates = SeparateFans(without(faces, dropped), newNode)
+	}
+
+	res.FaceIDs = make([]int64, len(faces))
+	for i := range res.FaceIDs {
+		res.FaceIDs[i] = -1
+	}
+	for i, f := range faces {
+		if dropped[i] {
+			continue
+		}
+		id := g.NewFaceID()
+		if err := g.AddFace(id, f...); err != nil {
+			return res, err
+		}
+		res.FaceIDs[i] = id
+	}
+	return res, nil
+}
+
+// without returns the faces that are not marked as dropped.
+func without(faces [][]graph.Node, dropped []bool) [][]graph.Node {
+	var kept [][]graph.Node
+	for i, f := range faces {
+		if !dropped[i] {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
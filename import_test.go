@@ -0,0 +1,90 @@
+package dcel
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestImport(t *testing.T) {
+	// Four triangles around the edge between nodes 0 and 1 with alternating
+	// directions of the edge.
+	fin := faceNodes([][]int64{{0, 1, 2}, {1, 0, 3}, {0, 1, 4}, {1, 0, 5}})
+	newFin := func() *Graph {
+		g := New(PointBase{})
+		for i, p := range []Point{{}, {X: 1}, {Y: -1}, {Y: 1}, {Z: -1}, {Z: 1}} {
+			addPointNode(g, int64(i), p)
+		}
+		return g
+	}
+
+	g := New(nil)
+	res, err := g.Import(fin, ImportOptions{})
+	if err == nil {
+		t.Error("dcel: expected error for non-manifold edge")
+	}
+	if !reflect.DeepEqual(res.NonManifoldEdges, [][2]int64{{0, 1}}) {
+		t.Errorf("dcel: wrong non-manifold edges: %v", res.NonManifoldEdges)
+	}
+	if len(g.nodes) != 0 {
+		t.Error("dcel: graph modified")
+	}
+
+	g = newFin()
+	res, err = g.Import(fin, ImportOptions{NonManifold: DropFaces})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(res.FaceIDs, []int64{0, 1, -1, -1}) || !reflect.DeepEqual(res.Dropped, []int{2, 3}) {
+		t.Errorf("dcel: wrong dropped faces: %v, %v", res.FaceIDs, res.Dropped)
+	}
+	if len(g.faces) != 2 || len(g.nodes) != 6 {
+		t.Errorf("dcel: wrong graph size after dropping faces")
+	}
+
+	g = newFin()
+	res, err = g.Import(fin, ImportOptions{NonManifold: SeparateSheets})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(res.FaceIDs, []int64{0, 1, 2, 3}) || res.Dropped != nil {
+		t.Errorf("dcel: wrong added faces: %v, %v", res.FaceIDs, res.Dropped)
+	}
+	if !reflect.DeepEqual(res.Duplicates, map[int64][]int64{0: {6}, 1: {7}}) {
+		t.Errorf("dcel: wrong duplicates: %v", res.Duplicates)
+	}
+	if h := g.Halfedge(6, 7); h == nil || h.Face() != g.Face(2) || h.Twin().Face() != g.Face(3) {
+		t.Error("dcel: second sheet not separated")
+	}
+	if point(g.nodes[7]) != (Point{X: 1}) {
+		t.Error("dcel: position of duplicate not copied")
+	}
+	topo := g.Topology()
+	if len(topo.Components) != 2 || topo.BoundaryLoops != 2 {
+		t.Errorf("dcel: wrong topology of sheets: %+v", topo)
+	}
+	if fin[2][0].ID() != 0 {
+		t.Error("dcel: imported faces modified")
+	}
+}
+
+func TestImportSeparateFans(t *testing.T) {
+	// Two closed fans of triangles around node 0.
+	cones := faceNodes([][]int64{
+		{0, 1, 2}, {0, 2, 3}, {0, 3, 1},
+		{0, 4, 5}, {0, 5, 6}, {0, 6, 4},
+	})
+	if _, err := New(nil).Import(cones, ImportOptions{}); err == nil {
+		t.Error("dcel: expected error for non-manifold node")
+	}
+	g := New(nil)
+	res, err := g.Import(cones, ImportOptions{SeparateFans: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(res.Duplicates, map[int64][]int64{0: {7}}) {
+		t.Errorf("dcel: wrong duplicates: %v", res.Duplicates)
+	}
+	if len(g.faces) != 6 || len(g.Topology().Components) != 2 {
+		t.Error("dcel: wrong separated fans")
+	}
+}
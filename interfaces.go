@@ -1,6 +1,6 @@
 package dcel
 
-import "github.com/gonum/graph"
+import "gonum.org/v1/gonum/graph"
 
 // Node is a graph node in the DCEL data structure.
 type Node interface {
@@ -14,6 +14,17 @@ type Node interface {
 	SetHalfedge(Halfedge)
 }
 
+// PointNode is a Node with a position in space. Geometric algorithms use the
+// positions of nodes that implement PointNode.
+type PointNode interface {
+	Node
+
+	// Point returns the position of the node.
+	Point() Point
+	// SetPoint sets the position of the node.
+	SetPoint(Point)
+}
+
 // Halfedge is a an oriented edge in the DCEL data structure.
 type Halfedge interface {
 	// From returns the origin node.
@@ -49,10 +60,11 @@ type Halfedge interface {
 
 // Edge is an undirected edge in the DCEL data structure.
 type Edge interface {
-	graph.Edge
+	graph.WeightedEdge
+	graph.WeightedLine
 
 	// ID returns an edge identifier unique within the graph.
-	ID() int
+	ID() int64
 
 	// Halfedges returns the two halfedges that form the edge.
 	Halfedges() (Halfedge, Halfedge)
@@ -60,10 +72,18 @@ type Edge interface {
 	SetHalfedges(Halfedge, Halfedge)
 }
 
+// WeightedEdge is an Edge that stores its weight.
+type WeightedEdge interface {
+	Edge
+
+	// SetWeight sets the weight of the edge.
+	SetWeight(float64)
+}
+
 // Face is a face in the DCEL data structure.
 type Face interface {
 	// ID returns a face identifier unique within the graph.
-	ID() int
+	ID() int64
 
 	// Halfedge returns an adjacent halfedge.
 	Halfedge() Halfedge
@@ -75,11 +95,11 @@ type Face interface {
 // DCEL data structure.
 type Items interface {
 	// NewNode returns a new node with the given id.
-	NewNode(int) Node
+	NewNode(int64) Node
 	// NewHalfedge returns a new halfedge.
 	NewHalfedge() Halfedge
 	// NewEdge returns a new edge with the given id.
-	NewEdge(int) Edge
+	NewEdge(int64) Edge
 	// NewFace returns a new face with the given id.
-	NewFace(int) Face
+	NewFace(int64) Face
 }
@@ -0,0 +1,199 @@
+package dcel
+
+import (
+	"fmt"
+	"math"
+)
+
+// Locator answers point location queries in a planar subdivision formed by a
+// Graph whose nodes carry positions in the XY plane. It indexes the nodes and
+// edges of the graph in a uniform grid of buckets.
+//
+// The graph must not be modified while the Locator is in use.
+type Locator struct {
+	g *Graph
+
+	box    Box
+	nx, ny int
+	dx, dy float64
+	nodes  [][]Node
+	edges  [][]Edge
+}
+
+// NewLocator returns a Locator for the graph g. The faces of g must be
+// counterclockwise and the edges of g must not cross. If a node of g does not
+// carry a position, an error is returned.
+func NewLocator(g *Graph) (*Locator, error) {
+	l := &Locator{g: g}
+
+	ids := g.nodeIDs()
+	if len(ids) == 0 {
+		return l, nil
+	}
+	l.box = Box{
+		Min: Point{X: math.Inf(1), Y: math.Inf(1)},
+		Max: Point{X: math.Inf(-1), Y: math.Inf(-1)},
+	}
+	for _, id := range ids {
+		p, ok := position(g.nodes[id])
+		if !ok {
+			return nil, fmt.Errorf("dcel: node %d does not carry a position", id)
+		}
+		l.box.Min.X, l.box.Max.X = math.Min(l.box.Min.X, p.X), math.Max(l.box.Max.X, p.X)
+		l.box.Min.Y, l.box.Max.Y = math.Min(l.box.Min.Y, p.Y), math.Max(l.box.Max.Y, p.Y)
+	}
+
+	// Use about one bucket per edge with square buckets.
+	w, h := l.box.Max.X-l.box.Min.X, l.box.Max.Y-l.box.Min.Y
+	n := math.Max(float64(len(g.edges)), 1)
+	switch {
+	case w == 0 && h == 0:
+		l.nx, l.ny = 1, 1
+	case w == 0:
+		l.nx, l.ny = 1, int(math.Ceil(n))
+	case h == 0:
+		l.nx, l.ny = int(math.Ceil(n)), 1
+	default:
+		size := math.Sqrt(w * h / n)
+		l.nx = int(math.Min(math.Ceil(w/size), n))
+		l.ny = int(math.Min(math.Ceil(h/size), n))
+	}
+	l.dx, l.dy = w/float64(l.nx), h/float64(l.ny)
+	l.nodes = make([][]Node, l.nx*l.ny)
+	l.edges = make([][]Edge, l.nx*l.ny)
+
+	for _, id := range ids {
+		u := g.nodes[id]
+		i, j := l.bucket(point(u))
+		l.nodes[j*l.nx+i] = append(l.nodes[j*l.nx+i], u)
+	}
+	for _, id := range g.edgeIDs() {
+		e := g.edges[id]
+		h1, h2 := e.Halfedges()
+		p, q := point(h1.From()), point(h2.From())
+		i0, j0 := l.bucket(Point{X: math.Min(p.X, q.X), Y: math.Min(p.Y, q.Y)})
+		i1, j1 := l.bucket(Point{X: math.Max(p.X, q.X), Y: math.Max(p.Y, q.Y)})
+		for j := j0; j <= j1; j++ {
+			for i := i0; i <= i1; i++ {
+				l.edges[j*l.nx+i] = append(l.edges[j*l.nx+i], e)
+			}
+		}
+	}
+	return l, nil
+}
+
+// bucket returns the column and row of the bucket that contains p. Points
+// outside the bounding box of the nodes are assigned to the nearest bucket.
+func (l *Locator) bucket(p Point) (i, j int) {
+	clamp := func(x float64, n int) int {
+		if x >= float64(n) {
+			return n - 1
+		}
+		if x < 0 || math.IsNaN(x) {
+			return 0
+		}
+		return int(x)
+	}
+	return clamp((p.X-l.box.Min.X)/l.dx, l.nx), clamp((p.Y-l.box.Min.Y)/l.dy, l.ny)
+}
+
+// Locate returns the node at the point (x, y), a halfedge of the edge that
+// contains the point in its interior or the face that contains the point in
+// its interior. Only one of the returned values is non-nil. If the point lies
+// outside of all faces and does not lie on a node or an edge, all returned
+// values are nil.
+func (l *Locator) Locate(x, y float64) (Face, Halfedge, Node) {
+	p := Point{X: x, Y: y}
+	if l.nx == 0 || !l.box.Contains(p) {
+		return nil, nil, nil
+	}
+
+	i, j := l.bucket(p)
+	for _, u := range l.nodes[j*l.nx+i] {
+		if samePosition(point(u), p) {
+			return nil, nil, u
+		}
+	}
+	for _, e := range l.edges[j*l.nx+i] {
+		h, t := e.Halfedges()
+		a, b := point(h.From()), point(t.From())
+		if orient(a, b, p) == 0 &&
+			math.Min(a.X, b.X) <= x && x <= math.Max(a.X, b.X) &&
+			math.Min(a.Y, b.Y) <= y && y <= math.Max(a.Y, b.Y) {
+			return nil, h, nil
+		}
+	}
+
+	// Cast a ray from p in the direction of the X axis and find the nearest
+	// node or edge that it hits. The face that contains p lies on the left of
+	// the edge or in the wedge around the node that faces p.
+	var (
+		hitX    = math.Inf(1)
+		hitEdge Halfedge
+		hitNode Node
+	)
+	for ; i < l.nx; i++ {
+		for _, u := range l.nodes[j*l.nx+i] {
+			q := point(u)
+			if q.Y == y && x < q.X && q.X < hitX {
+				hitX, hitEdge, hitNode = q.X, nil, u
+			}
+		}
+		for _, e := range l.edges[j*l.nx+i] {
+			h, t := e.Halfedges()
+			a, b := point(h.From()), point(t.From())
+			if a.Y > b.Y {
+				h, a, b = t, b, a
+			}
+			if !(a.Y < y && y < b.Y) || orient(a, b, p) <= 0 {
+				continue
+			}
+			cx := a.X + (y-a.Y)*(b.X-a.X)/(b.Y-a.Y)
+			if cx < hitX {
+				hitX, hitEdge, hitNode = cx, h, nil
+			}
+		}
+		if hitX <= l.box.Min.X+float64(i+1)*l.dx {
+			break
+		}
+	}
+	switch {
+	case hitEdge != nil:
+		return hitEdge.Face(), nil, nil
+	case hitNode != nil:
+		return wedge(hitNode, p), nil, nil
+	}
+	return nil, nil, nil
+}
+
+// wedge returns the face around the node u that contains the points near u in
+// the direction of p. p must not lie on an edge from u.
+func wedge(u Node, p Point) Face {
+	start := u.Halfedge()
+	if start == nil {
+		return nil
+	}
+	a := point(u)
+	for h := start; ; {
+		next := h.Prev().Twin()
+		if next == h {
+			return h.Face()
+		}
+		if inWedge(a, point(h.Twin().From()), point(next.Twin().From()), p) {
+			return h.Face()
+		}
+		h = next
+		if h == start {
+			return nil
+		}
+	}
+}
+
+// inWedge returns whether p lies strictly inside the wedge with apex o that
+// extends counterclockwise from the direction of a to the direction of b.
+func inWedge(o, a, b, p Point) bool {
+	if orient(o, a, b) > 0 {
+		return orient(o, a, p) > 0 && orient(o, b, p) < 0
+	}
+	return orient(o, a, p) > 0 || orient(o, b, p) < 0
+}
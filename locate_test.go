@@ -0,0 +1,161 @@
+package dcel
+
+import (
+	"math/rand/v2"
+	"testing"
+)
+
+func TestLocatorVoronoi(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(1, 4))
+	sites := make([]Point, 200)
+	for i := range sites {
+		sites[i] = Point{X: rnd.Float64(), Y: rnd.Float64()}
+	}
+	g, err := Voronoi(nil, sites, Box{Max: Point{X: 1, Y: 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := NewLocator(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k := 0; k < 1000; k++ {
+		x, y := 1.2*rnd.Float64()-0.1, 1.2*rnd.Float64()-0.1
+		f, h, u := l.Locate(x, y)
+		if h != nil || u != nil {
+			t.Fatalf("dcel: unexpected location of (%v, %v) on an edge or a node", x, y)
+		}
+		// The cells are convex, so compare with a linear search.
+		var want Face
+		for _, c := range g.Faces() {
+			if (Box{Max: Point{X: 1, Y: 1}}).Contains(Point{X: x, Y: y}) && convexContains(g, c, Point{X: x, Y: y}) {
+				want = c
+			}
+		}
+		if f != want {
+			t.Errorf("dcel: wrong face located at (%v, %v)", x, y)
+		}
+	}
+}
+
+func TestLocatorGrid(t *testing.T) {
+	var sites []Point
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			sites = append(sites, Point{X: float64(i) + 0.5, Y: float64(j) + 0.5})
+		}
+	}
+	g, err := Voronoi(nil, sites, Box{Max: Point{X: 4, Y: 4}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.RemoveFace(g.Face(5))
+	l, err := NewLocator(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		x, y       float64
+		face       int64
+		edge, node bool
+	}{
+		{x: 0.5, y: 0.5, face: 0},
+		{x: 3.5, y: 2.1, face: 14},
+		{x: 2.5, y: 1.9, face: 9},
+		{x: 1.5, y: 1.5, face: -1}, // Hole.
+		{x: 5, y: 1, face: -1},
+		{x: -1, y: 1, face: -1},
+		{x: 1, y: 1, node: true},
+		{x: 4, y: 0, node: true},
+		{x: 1, y: 1.5, edge: true},
+		{x: 0, y: 3.5, edge: true},
+	} {
+		f, h, u := l.Locate(test.x, test.y)
+		switch {
+		case test.node:
+			if u == nil || !samePosition(point(u), Point{X: test.x, Y: test.y}) {
+				t.Errorf("dcel: node not located at (%v, %v)", test.x, test.y)
+			}
+		case test.edge:
+			if h == nil || orient(point(h.From()), point(h.Twin().From()), Point{X: test.x, Y: test.y}) != 0 {
+				t.Errorf("dcel: edge not located at (%v, %v)", test.x, test.y)
+			}
+		case test.face < 0:
+			if f != nil || h != nil || u != nil {
+				t.Errorf("dcel: unexpected location of (%v, %v)", test.x, test.y)
+			}
+		default:
+			if f == nil || f.ID() != test.face {
+				t.Errorf("dcel: face %d not located at (%v, %v)", test.face, test.x, test.y)
+			}
+		}
+	}
+}
+
+func TestLocatorLattice(t *testing.T) {
+	// Rays cast from points with integer Y coordinate pass through nodes.
+	rnd := rand.New(rand.NewPCG(1, 5))
+	seen := make(map[Point]bool)
+	var points []Point
+	for len(points) < 60 {
+		p := Point{X: float64(rnd.IntN(10)), Y: float64(rnd.IntN(10))}
+		if !seen[p] {
+			seen[p] = true
+			points = append(points, p)
+		}
+	}
+	tri, err := NewDelaunay(nil, points)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := tri.Graph()
+	l, err := NewLocator(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k := 0; k < 2000; k++ {
+		p := Point{X: float64(rnd.IntN(44))/4 - 0.5, Y: float64(rnd.IntN(22))/2 - 0.5}
+		f, h, u := l.Locate(p.X, p.Y)
+		switch {
+		case u != nil:
+			if !samePosition(point(u), p) {
+				t.Errorf("dcel: wrong node located at %v", p)
+			}
+		case h != nil:
+			if orient(point(h.From()), point(h.Twin().From()), p) != 0 {
+				t.Errorf("dcel: wrong edge located at %v", p)
+			}
+		default:
+			var want Face
+			for _, c := range g.Faces() {
+				if convexContains(g, c, p) {
+					want = c
+				}
+			}
+			if f != want {
+				t.Errorf("dcel: wrong face located at %v", p)
+			}
+			if seen[p] {
+				t.Errorf("dcel: node not located at %v", p)
+			}
+			for _, id := range g.edgeIDs() {
+				h1, h2 := g.edges[id].Halfedges()
+				a, b := point(h1.From()), point(h2.From())
+				if orient(a, b, p) == 0 && p.Sub(a).Dot(p.Sub(b)) < 0 {
+					t.Errorf("dcel: edge not located at %v", p)
+				}
+			}
+		}
+	}
+}
+
+// convexContains returns whether the convex face f contains p in its interior.
+func convexContains(g *Graph, f Face, p Point) bool {
+	for _, h := range g.HalfedgesAround(f) {
+		if orient(point(h.From()), point(h.Twin().From()), p) <= 0 {
+			return false
+		}
+	}
+	return true
+}
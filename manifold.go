@@ -0,0 +1,221 @@
+package dcel
+
+import (
+	"sort"
+
+	"gonum.org/v1/gonum/graph"
+)
+
+// NonManifoldNodes returns the nodes of the graph whose adjacent faces form
+// more than one fan, ordered by ID. Faces are in the same fan if they are
+// connected through edges adjacent to the node.
+func (g *Graph) NonManifoldNodes() []Node {
+	var nodes []Node
+	for _, id := range g.nodeIDs() {
+		if u := g.nodes[id]; len(fans(u)) > 1 {
+			nodes = append(nodes, u)
+		}
+	}
+	return nodes
+}
+
+// SplitNonManifoldNodes duplicates each node returned by NonManifoldNodes
+// into one node per fan of faces. The first fan around the node keeps the
+// node, and the edges and faces of each other fan are moved to a new node with
+// the same position and node property values. Edges without adjacent faces
+// stay at the original node.
+//
+// The returned map holds the IDs of the new nodes for each split node.
+// Observers are notified of the added nodes and of the reconnected halfedges.
+func (g *Graph) SplitNonManifoldNodes() map[int64][]int64 {
+	split := make(map[int64][]int64)
+	for _, u := range g.NonManifoldNodes() {
+		for _, fan := range fans(u)[1:] {
+			v := g.AddNode(g.NewNodeID())
+			if p, ok := position(u); ok {
+				setPoint(v, p)
+			}
+			g.props.copyNode(u.ID(), v.ID())
+
+			// Close the loop of halfedges around v and the gap left around
+			// u.
+			first, last := fan[0], fan[len(fan)-1].Twin()
+			in, out := first.Prev(), last.Next()
+			last.SetNext(first)
+			first.SetPrev(last)
+			in.SetNext(out)
+			out.SetPrev(in)
+			for _, h := range fan {
+				h.SetFrom(v)
+			}
+			v.SetHalfedge(first)
+			u.SetHalfedge(out)
+
+			g.reconnected(last, first)
+			g.reconnected(in, out)
+			split[u.ID()] = append(split[u.ID()], v.ID())
+		}
+	}
+	return split
+}
+
+// fans returns the outgoing halfedges from u grouped by the fans of faces
+// around u. Each group starts with a halfedge without an adjacent face that is
+// followed by the halfedges of the faces of the fan. If u has no outgoing
+// halfedge without a face, fans returns nil.
+func fans(u Node) [][]Halfedge {
+	start := u.Halfedge()
+	if start == nil {
+		return nil
+	}
+	// Find a halfedge without a face to start from.
+	for start.Face() != nil {
+		start = start.Twin().Next()
+		if start == u.Halfedge() {
+			return nil
+		}
+	}
+	var groups [][]Halfedge
+	for h := start; ; {
+		group := []Halfedge{h}
+		for h = h.Twin().Next(); h.Face() != nil; h = h.Twin().Next() {
+			group = append(group, h)
+		}
+		if len(group) > 1 {
+			groups = append(groups, group)
+		}
+		if h == start {
+			return groups
+		}
+	}
+}
+
+// SeparateFans makes the faces given by their nodes representable by a Graph
+// by replacing, in each face, nodes whose adjacent faces form more than one
+// fan. Faces are in the same fan around a node if they are connected through
+// edges adjacent to the node that are shared by exactly two faces. The faces
+// of the fan with the smallest face index keep the node and each other fan
+// gets a duplicate returned by newNode, which must return a node with a new
+// unique ID.
+//
+// The returned map holds the IDs of the duplicates for each replaced node.
+func SeparateFans(faces [][]graph.Node, newNode func(u graph.Node) graph.Node) map[int64][]int64 {
+	edges, offset := faceEdges(faces)
+	var pairs [][2]edgeSide
+	for _, sides := range edges {
+		if len(sides) == 2 {
+			pairs = append(pairs, [2]edgeSide{sides[0], sides[1]})
+		}
+	}
+	return separateFans(faces, offset, pairs, newNode)
+}
+
+// edgeSide is an occurrence of an edge in a face.
+type edgeSide struct {
+	face int
+	// from and to are the corners of the face at the end nodes of the edge
+	// with the smaller and the larger ID.
+	from, to int
+	// forward is whether the face traverses the edge from the node with the
+	// smaller ID.
+	forward bool
+}
+
+// faceEdges returns the occurrences of edges in faces keyed by edgeKey in
+// the order of faces. Corners of faces are numbered consecutively and the
+// corners of the face i start at offset[i].
+func faceEdges(faces [][]graph.Node) (edges map[[2]int64][]edgeSide, offset []int) {
+	offset = make([]int, len(faces)+1)
+	for i, f := range faces {
+		offset[i+1] = offset[i] + len(f)
+	}
+	edges = make(map[[2]int64][]edgeSide)
+	for i, f := range faces {
+		for k, u := range f {
+			j := (k + 1) % len(f)
+			v := f[j]
+			if u.ID() == v.ID() {
+				continue
+			}
+			s := edgeSide{face: i, from: offset[i] + k, to: offset[i] + j, forward: true}
+			if u.ID() > v.ID() {
+				s.from, s.to, s.forward = s.to, s.from, false
+			}
+			key := edgeKey(u.ID(), v.ID())
+			edges[key] = append(edges[key], s)
+		}
+	}
+	return edges, offset
+}
+
+// separateFans replaces nodes in faces so that the corners at each node form
+// a single fan. Corners are in the same fan if they are connected by the
+// given pairs of occurrences of edges. See SeparateFans for details.
+func separateFans(faces [][]graph.Node, offset []int, pairs [][2]edgeSide, newNode func(u graph.Node) graph.Node) map[int64][]int64 {
+	parent := make([]int, offset[len(faces)])
+	for c := range parent {
+		parent[c] = c
+	}
+	var root func(c int) int
+	root = func(c int) int {
+		if parent[c] != c {
+			parent[c] = root(parent[c])
+		}
+		return parent[c]
+	}
+	union := func(a, b int) {
+		a, b = root(a), root(b)
+		if a > b {
+			a, b = b, a
+		}
+		parent[b] = a
+	}
+	for _, p := range pairs {
+		union(p[0].from, p[1].from)
+		union(p[0].to, p[1].to)
+	}
+
+	// Group the corners of each node by fan. Roots are the smallest corners
+	// of fans so the fans are ordered by the smallest face index.
+	fanNodes := make(map[int64]map[int]graph.Node)
+	var ids []int64
+	for i, f := range faces {
+		for k, u := range f {
+			m, ok := fanNodes[u.ID()]
+			if !ok {
+				m = make(map[int]graph.Node)
+				fanNodes[u.ID()] = m
+				ids = append(ids, u.ID())
+			}
+			m[root(offset[i]+k)] = u
+		}
+	}
+	split := make(map[int64][]int64)
+	for _, id := range ids {
+		m := fanNodes[id]
+		if len(m) < 2 {
+			continue
+		}
+		roots := make([]int, 0, len(m))
+		for r := range m {
+			roots = append(roots, r)
+		}
+		sort.Ints(roots)
+		for _, r := range roots[1:] {
+			v := newNode(m[r])
+			m[r] = v
+			split[id] = append(split[id], v.ID())
+		}
+	}
+	if len(split) == 0 {
+		return split
+	}
+	for i, f := range faces {
+		for k, u := range f {
+			if _, ok := split[u.ID()]; ok {
+				f[k] = fanNodes[u.ID()][root(offset[i]+k)]
+			}
+		}
+	}
+	return split
+}
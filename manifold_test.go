@@ -0,0 +1,117 @@
+package dcel
+
+import (
+	"reflect"
+	"testing"
+
+	"gonum.org/v1/gonum/graph"
+)
+
+func TestSplitNonManifoldNodes(t *testing.T) {
+	// Two triangles touching at node 0 and a third triangle touching the
+	// first one at node 1.
+	g := New(PointBase{})
+	for i, p := range []Point{{}, {X: 1, Y: -1}, {X: 1, Y: 1}, {X: -1, Y: 1}, {X: -1, Y: -1}, {X: 2, Y: -2}, {X: 2, Y: 0}} {
+		addPointNode(g, int64(i), p)
+	}
+	for _, f := range [][]int64{{0, 1, 2}, {0, 3, 4}, {1, 5, 6}} {
+		if err := g.AddFace(g.NewFaceID(), NodeID(f[0]), NodeID(f[1]), NodeID(f[2])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A dangling edge does not form a fan.
+	if _, err := g.addEdge(NodeID(2), NodeID(7)); err != nil {
+		t.Fatal(err)
+	}
+	label, err := AddNodeProperty[string](g, "label")
+	if err != nil {
+		t.Fatal(err)
+	}
+	label.Set(g.Node(0).(Node), "apex")
+
+	var ids []int64
+	for _, u := range g.NonManifoldNodes() {
+		ids = append(ids, u.ID())
+	}
+	if !reflect.DeepEqual(ids, []int64{0, 1}) {
+		t.Errorf("dcel: wrong non-manifold nodes: %v", ids)
+	}
+
+	split := g.SplitNonManifoldNodes()
+	want := map[int64][]int64{0: {8}, 1: {9}}
+	if !reflect.DeepEqual(split, want) {
+		t.Errorf("dcel: wrong split nodes: got %v, want %v", split, want)
+	}
+	checkLinks(t, g)
+	if nodes := g.NonManifoldNodes(); len(nodes) != 0 {
+		t.Errorf("dcel: non-manifold nodes after split: %v", nodes)
+	}
+	topo := g.Topology()
+	if len(topo.Components) != 3 || topo.Nodes != 10 || topo.Edges != 10 {
+		t.Errorf("dcel: wrong topology after split: %+v", topo)
+	}
+	for _, f := range g.Faces() {
+		if len(g.HalfedgesAround(f)) != 3 || faceArea(g, f) <= 0 {
+			t.Errorf("dcel: face %d broken by split", f.ID())
+		}
+	}
+	u := g.Node(8).(Node)
+	if point(u) != (Point{}) || label.Get(u) != "apex" {
+		t.Error("dcel: position or properties of split node not copied")
+	}
+}
+
+func TestSeparateFans(t *testing.T) {
+	// Two closed fans of triangles around node 0 and a triangle at its tip.
+	ids := [][]int64{
+		{0, 1, 2}, {0, 2, 3}, {0, 3, 1},
+		{0, 4, 5}, {0, 5, 6}, {0, 6, 4},
+		{7, 8, 0},
+	}
+	g := New(nil)
+	var err error
+	for i, f := range faceNodes(ids) {
+		if err = g.AddFace(int64(i), f...); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		t.Fatal("dcel: expected error for non-manifold faces")
+	}
+
+	faces := faceNodes(ids)
+	next := int64(100)
+	split := SeparateFans(faces, func(u graph.Node) graph.Node {
+		next++
+		return NodeID(next - 1)
+	})
+	if !reflect.DeepEqual(split, map[int64][]int64{0: {100, 101}}) {
+		t.Errorf("dcel: wrong split nodes: %v", split)
+	}
+	for i, f := range faces {
+		want := int64(0)
+		switch {
+		case 3 <= i && i < 6:
+			want = 100
+		case i == 6:
+			want = 101
+		}
+		for k, u := range f {
+			if id := ids[i][k]; id != 0 && u.ID() != id || id == 0 && u.ID() != want {
+				t.Errorf("dcel: wrong node %d of face %d: got %d", k, i, u.ID())
+			}
+		}
+	}
+	g = New(nil)
+	for i, f := range faces {
+		if err := g.AddFace(int64(i), f...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Faces that are already manifold are kept.
+	faces = faceNodes([][]int64{{0, 1, 2}, {0, 2, 3}})
+	if split := SeparateFans(faces, nil); len(split) != 0 {
+		t.Errorf("dcel: unexpected split nodes: %v", split)
+	}
+}
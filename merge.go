@@ -0,0 +1,296 @@
+package dcel
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"sort"
+)
+
+// IDMap maps the IDs of the nodes, edges and faces of one graph to the IDs of
+// the corresponding elements of another graph.
+type IDMap struct {
+	Nodes map[int64]int64
+	Edges map[int64]int64
+	Faces map[int64]int64
+}
+
+// Merge adds a copy of the nodes, edges and faces of other to g and returns
+// the map from the IDs in other to the IDs of the copies in g. The copies get
+// IDs returned by NewNodeID and NewFaceID and new edge IDs in the order of the
+// IDs in other. The copies are connected in the same way as the elements of
+// other, so the copy of a connected component of other is a separate
+// connected component of g. The positions of nodes and the weights of edges
+// that store them are copied but other data of the elements is not.
+//
+// The values of the properties registered in other are copied to the
+// properties of g with the same name and type. A property of other that g
+// does not have is registered in g, where it can be found by name, and a
+// property of g with the same name but another type is left unchanged. The
+// properties of other keep their values.
+//
+// Observers of g are notified of the added elements after their property values
+// have been copied.
+func (g *Graph) Merge(other *Graph) IDMap {
+	var (
+		nodeIDs = other.nodeIDs()
+		edgeIDs = other.edgeIDs()
+		faceIDs = other.faceIDs()
+
+		m = IDMap{
+			Nodes: make(map[int64]int64, len(nodeIDs)),
+			Edges: make(map[int64]int64, len(edgeIDs)),
+			Faces: make(map[int64]int64, len(faceIDs)),
+		}
+		nodes  = make(map[int64]Node, len(nodeIDs))
+		faces  = make(map[int64]Face, len(faceIDs))
+		hedges = make(map[Halfedge]Halfedge, 2*len(edgeIDs))
+		edges  = make([]Edge, 0, len(edgeIDs))
+	)
+	for _, id := range nodeIDs {
+		v := g.AddNode(g.NewNodeID())
+		if p, ok := position(other.nodes[id]); ok {
+			setPoint(v, p)
+		}
+		nodes[id] = v
+		m.Nodes[id] = v.ID()
+	}
+	for _, id := range edgeIDs {
+		e := g.newEdge(g.newEdgeID())
+		if we, ok := e.(WeightedEdge); ok {
+			we.SetWeight(other.edges[id].Weight())
+		}
+		h1, h2 := other.edges[id].Halfedges()
+		c1, c2 := e.Halfedges()
+		hedges[h1] = c1
+		hedges[h2] = c2
+		edges = append(edges, e)
+		m.Edges[id] = e.ID()
+	}
+	for _, id := range faceIDs {
+		f := g.items.NewFace(g.NewFaceID())
+		faces[id] = f
+		m.Faces[id] = f.ID()
+	}
+
+	// Connect the copies.
+	for h, c := range hedges {
+		c.SetFrom(nodes[h.From().ID()])
+		c.SetNext(hedges[h.Next()])
+		c.SetPrev(hedges[h.Prev()])
+		if f := h.Face(); f != nil {
+			c.SetFace(faces[f.ID()])
+		}
+	}
+	for _, id := range nodeIDs {
+		if h := other.nodes[id].Halfedge(); h != nil {
+			nodes[id].SetHalfedge(hedges[h])
+		}
+	}
+	for _, id := range faceIDs {
+		faces[id].SetHalfedge(hedges[other.faces[id].Halfedge()])
+	}
+
+	g.props.merge(&other.props, m, hedges)
+
+	for _, e := range edges {
+		g.insertEdge(e)
+	}
+	for _, id := range faceIDs {
+		g.insertFace(faces[id])
+	}
+	return m
+}
+
+// insertEdge adds the connected edge e to the edges of g.
+func (g *Graph) insertEdge(e Edge) {
+	id := e.ID()
+	g.edges[id] = e
+	delete(g.freeEdges, id)
+	g.nextEdgeID = nextID(g.nextEdgeID, id)
+	for _, o := range g.observers {
+		o.EdgeAdded(e)
+	}
+}
+
+// insertFace adds the connected face f to the faces of g.
+func (g *Graph) insertFace(f Face) {
+	id := f.ID()
+	g.faces[id] = f
+	delete(g.freeFaces, id)
+	g.nextFaceID = nextID(g.nextFaceID, id)
+	for _, o := range g.observers {
+		o.FaceAdded(f)
+	}
+}
+
+// Stitch glues the boundary loop that contains the halfedge a to the boundary
+// loop that contains the halfedge b. The loops must have the same length and
+// are glued in opposite directions, so that a.From() is merged with the end
+// node of b and the end node of a with b.From(), and so on along the loops.
+// Corresponding nodes are merged into the node with the smaller ID and the
+// edges of corresponding halfedges are stitched as by WeldNodes.
+//
+// The halfedge a must belong to g. If b belongs to another graph, the
+// connected component of b is copied into g as by Merge and the loop of the
+// copy of b is stitched, so the copied nodes are merged into the nodes of g.
+// The other graph is not modified. The property values of the component are
+// not copied because the graph that holds them is not known from b; to keep
+// them, Merge the other graph first and stitch the copy of b.
+//
+// If a is not a halfedge of g or b is not a halfedge of a graph, if a or b has
+// an adjacent face, if the loops are the same or have different lengths, or if
+// the loops cannot be stitched as described by WeldNodes, an error is returned
+// and g is not modified.
+func (g *Graph) Stitch(a, b Halfedge) error {
+	if a.From() == nil || g.Halfedge(a.From().ID(), a.Twin().From().ID()) != a {
+		return errors.New("dcel: cannot stitch, halfedge does not belong to the graph")
+	}
+	if b.From() == nil || b.Twin() == nil || b.Twin().From() == nil {
+		return errors.New("dcel: cannot stitch, halfedge does not belong to a graph")
+	}
+	for _, h := range [2]Halfedge{a, b} {
+		if h.Face() != nil {
+			return errors.New("dcel: cannot stitch, halfedge is not on a boundary")
+		}
+	}
+	if g.Halfedge(b.From().ID(), b.Twin().From().ID()) == b {
+		p, err := g.planStitch(a, b)
+		if err != nil {
+			return err
+		}
+		p.apply()
+		return nil
+	}
+
+	// Copy the component of b without notifying the observers so that the
+	// copy can be removed silently if the loops cannot be stitched.
+	saved := g.saveIDs()
+	observers := g.observers
+	g.observers = nil
+	m := g.Merge(component(b))
+	g.observers = observers
+	p, err := g.planStitch(a, g.Halfedge(m.Nodes[b.From().ID()], m.Nodes[b.Twin().From().ID()]))
+	if err != nil {
+		for _, id := range m.Faces {
+			delete(g.faces, id)
+		}
+		for _, id := range m.Edges {
+			delete(g.edges, id)
+		}
+		for _, id := range m.Nodes {
+			delete(g.nodes, id)
+		}
+		saved()
+		return err
+	}
+	for _, id := range sortedValues(m.Nodes) {
+		for _, o := range g.observers {
+			o.NodeAdded(g.nodes[id])
+		}
+	}
+	for _, id := range sortedValues(m.Edges) {
+		for _, o := range g.observers {
+			o.EdgeAdded(g.edges[id])
+		}
+	}
+	for _, id := range sortedValues(m.Faces) {
+		for _, o := range g.observers {
+			o.FaceAdded(g.faces[id])
+		}
+	}
+	p.apply()
+	return nil
+}
+
+// planStitch plans the stitching of the boundary loops of the halfedges a and
+// b of g without an adjacent face. See Stitch for details.
+func (g *Graph) planStitch(a, b Halfedge) (*weldPlan, error) {
+	la, lb := g.Loop(a), g.Loop(b)
+	for _, h := range la {
+		if h == b {
+			return nil, errors.New("dcel: cannot stitch a boundary loop to itself")
+		}
+	}
+	if len(la) != len(lb) {
+		return nil, fmt.Errorf("dcel: cannot stitch boundary loops of lengths %d and %d", len(la), len(lb))
+	}
+
+	// Merge the corresponding nodes transitively into the node with the
+	// smallest ID.
+	parent := make(map[int64]int64)
+	var root func(id int64) int64
+	root = func(id int64) int64 {
+		p, ok := parent[id]
+		if !ok || p == id {
+			return id
+		}
+		parent[id] = root(p)
+		return parent[id]
+	}
+	n := len(la)
+	for i, h := range la {
+		// The halfedges of the loop of b are traversed backwards.
+		x, y := root(h.From().ID()), root(lb[(n-i)%n].Twin().From().ID())
+		if x > y {
+			x, y = y, x
+		}
+		if x != y {
+			parent[y] = x
+		}
+	}
+	merged := make(map[int64]int64)
+	for id := range parent {
+		if r := root(id); r != id {
+			merged[id] = r
+		}
+	}
+	return g.planWeld(merged)
+}
+
+// component returns a graph that holds the nodes, edges and faces of the
+// connected component of h. The graph shares the elements with the graph of h
+// and must only be read.
+func component(h Halfedge) *Graph {
+	c := New(nil)
+	stack := []Halfedge{h}
+	for len(stack) > 0 {
+		h := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		e := h.Edge()
+		if c.edges[e.ID()] == e {
+			continue
+		}
+		c.edges[e.ID()] = e
+		for _, h := range [2]Halfedge{h, h.Twin()} {
+			u := h.From()
+			c.nodes[u.ID()] = u
+			if f := h.Face(); f != nil {
+				c.faces[f.ID()] = f
+			}
+			stack = append(stack, h.Next())
+		}
+	}
+	return c
+}
+
+// saveIDs returns a function that restores the ID counters and the sets of
+// released IDs of g to their current state.
+func (g *Graph) saveIDs() (restore func()) {
+	next := [3]int64{g.nextNodeID, g.nextEdgeID, g.nextFaceID}
+	free := [3]map[int64]struct{}{maps.Clone(g.freeNodes), maps.Clone(g.freeEdges), maps.Clone(g.freeFaces)}
+	return func() {
+		g.nextNodeID, g.nextEdgeID, g.nextFaceID = next[0], next[1], next[2]
+		g.freeNodes, g.freeEdges, g.freeFaces = free[0], free[1], free[2]
+	}
+}
+
+// sortedValues returns the values of m in increasing order.
+func sortedValues(m map[int64]int64) []int64 {
+	values := make([]int64, 0, len(m))
+	for _, v := range m {
+		values = append(values, v)
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+	return values
+}
@@ -0,0 +1,194 @@
+package dcel
+
+import "testing"
+
+func TestMerge(t *testing.T) {
+	g := newTriangulatedCube()
+	r := &recorder{g: g}
+	g.AddObserver(r)
+
+	other := newTriangulatedCube()
+	other.RemoveFace(other.Face(0))
+	other.Halfedge(0, 1).Edge().(WeightedEdge).SetWeight(5)
+	m := g.Merge(other)
+	checkLinks(t, g)
+	if len(m.Nodes) != 8 || len(m.Edges) != 18 || len(m.Faces) != 11 {
+		t.Errorf("dcel: wrong size of ID map: %d nodes, %d edges, %d faces", len(m.Nodes), len(m.Edges), len(m.Faces))
+	}
+	if m.Nodes[0] != 8 || m.Faces[1] != 12 {
+		t.Errorf("dcel: wrong IDs of copies: %v, %v", m.Nodes, m.Faces)
+	}
+	if len(r.events) != 8+18+11 {
+		t.Errorf("dcel: wrong number of events: %d", len(r.events))
+	}
+	for id, cid := range m.Nodes {
+		if point(g.nodes[cid]) != point(other.nodes[id]) {
+			t.Errorf("dcel: position of node %d not copied", id)
+		}
+	}
+	for id, cid := range m.Faces {
+		got, want := g.HalfedgesAround(g.faces[cid]), other.HalfedgesAround(other.faces[id])
+		for i := range want {
+			if got[i].From().ID() != m.Nodes[want[i].From().ID()] {
+				t.Errorf("dcel: face %d not copied", id)
+			}
+		}
+	}
+	for id, cid := range m.Edges {
+		if w, want := g.edges[cid].Weight(), other.edges[id].Weight(); w != want {
+			t.Errorf("dcel: weight of edge %d not copied: got %v, want %v", id, w, want)
+		}
+	}
+	if w, _ := g.Weight(m.Nodes[0], m.Nodes[1]); w != 5 {
+		t.Errorf("dcel: wrong weight of copied edge: got %v, want 5", w)
+	}
+	topo := g.Topology()
+	if len(topo.Components) != 2 || !topo.Components[0].Closed || topo.Components[1].BoundaryLoops != 1 {
+		t.Errorf("dcel: wrong topology after merge: %+v", topo.Components)
+	}
+	if len(other.nodes) != 8 || len(other.faces) != 11 {
+		t.Error("dcel: merged graph modified")
+	}
+}
+
+func TestMergeProperties(t *testing.T) {
+	g := newTriangulatedCube()
+	other := newTriangulatedCube()
+	gLabels, _ := AddNodeProperty[string](g, "label")
+	gLabels.Set(g.nodes[0], "g")
+	gTags, _ := AddFaceProperty[int](g, "tag")
+	labels, _ := AddNodeProperty[string](other, "label")
+	tags, _ := AddFaceProperty[string](other, "tag")
+	weights, _ := AddEdgeProperty[float64](other, "weight")
+	sides, _ := AddHalfedgeProperty[bool](other, "side")
+	labels.Set(other.nodes[3], "corner")
+	tags.Set(other.faces[1], "ignored")
+	weights.Set(other.edges[2], 0.5)
+	h := other.Halfedge(0, 1)
+	sides.Set(h, true)
+
+	m := g.Merge(other)
+	if gLabels.Get(g.nodes[m.Nodes[3]]) != "corner" || gLabels.Get(g.nodes[0]) != "g" || gLabels.Len() != 2 {
+		t.Error("dcel: node property values not copied")
+	}
+	if gTags.Len() != 0 {
+		t.Error("dcel: values of a property of another type copied")
+	}
+	if w, ok := FindEdgeProperty[float64](g, "weight"); !ok || w.Get(g.edges[m.Edges[2]]) != 0.5 || w.Len() != 1 {
+		t.Error("dcel: edge property not registered or values not copied")
+	}
+	s, ok := FindHalfedgeProperty[bool](g, "side")
+	if !ok || !s.Get(g.Halfedge(m.Nodes[0], m.Nodes[1])) || s.Len() != 1 {
+		t.Error("dcel: halfedge property not registered or values not copied")
+	}
+	if labels.Len() != 1 || !sides.Get(h) {
+		t.Error("dcel: property values of merged graph modified")
+	}
+}
+
+func TestStitch(t *testing.T) {
+	// A box without its top and a separate lid.
+	g := newTriangulatedCube()
+	g.RemoveEdge(4, 7)
+	lid := newMesh([]Point{{-1, -1, 1}, {1, -1, 1}, {-1, 1, 1}, {1, 1, 1}}, [][]int{{0, 1, 3}, {0, 3, 2}})
+
+	m := g.Merge(lid)
+	a := g.Halfedge(4, 5)
+	b := g.Halfedge(m.Nodes[1], m.Nodes[0])
+	if err := g.Stitch(a, g.Halfedge(5, 7)); err == nil {
+		t.Error("dcel: expected error for a loop stitched to itself")
+	}
+	if err := g.Stitch(a, b.Twin()); err == nil {
+		t.Error("dcel: expected error for a halfedge with a face")
+	}
+	if err := g.Stitch(a, b); err != nil {
+		t.Fatal(err)
+	}
+	checkLinks(t, g)
+	checkClosed(t, g, 12, 3)
+	if topo := g.Topology(); topo.Nodes != 8 || topo.Edges != 18 || len(topo.Components) != 1 {
+		t.Errorf("dcel: wrong topology after stitching: %+v", topo)
+	}
+
+	// A lid of another graph.
+	g = newTriangulatedCube()
+	g.RemoveEdge(4, 7)
+	r := &recorder{g: g}
+	g.AddObserver(r)
+	if err := g.Stitch(g.Halfedge(4, 5), lid.Halfedge(1, 0)); err != nil {
+		t.Fatal(err)
+	}
+	checkLinks(t, g)
+	checkClosed(t, g, 12, 3)
+	if topo := g.Topology(); topo.Nodes != 8 || topo.Edges != 18 || len(topo.Components) != 1 {
+		t.Errorf("dcel: wrong topology after stitching another graph: %+v", topo)
+	}
+	var kinds string
+	for _, ev := range r.events[:11] {
+		kinds += ev[:2]
+	}
+	if kinds != "+n+n+n+n+e+e+e+e+e+f+f" {
+		t.Errorf("dcel: copy of the lid not reported first: %v", r.events)
+	}
+	if lid.Nodes().Len() != 4 || len(lid.BoundaryLoops()) != 1 {
+		t.Error("dcel: stitched graph modified")
+	}
+
+	// Boundary loops of different lengths.
+	g = newTriangulatedCube()
+	g.RemoveEdge(4, 7)
+	m = g.Merge(newMesh([]Point{{}, {X: 1}, {Y: 1}}, [][]int{{0, 1, 2}}))
+	if err := g.Stitch(g.Halfedge(4, 5), g.Halfedge(m.Nodes[1], m.Nodes[0])); err == nil {
+		t.Error("dcel: expected error for loops of different lengths")
+	}
+}
+
+func TestStitchError(t *testing.T) {
+	// Two bowties with opposite orientations. Stitching their boundary loops
+	// passes the checks, but the faces around the merged centers would form
+	// two closed fans that cannot be added back.
+	g := New(nil)
+	for _, f := range [][]int64{{0, 1, 2}, {2, 3, 4}, {12, 11, 10}, {14, 13, 12}} {
+		if err := g.AddFace(g.NewFaceID(), NodeID(f[0]), NodeID(f[1]), NodeID(f[2])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r := &recorder{g: g}
+	g.AddObserver(r)
+	if err := g.Stitch(g.Halfedge(1, 0), g.Halfedge(10, 11)); err == nil {
+		t.Fatal("dcel: expected error for faces that cannot be added back")
+	}
+	if g.Nodes().Len() != 10 || g.Edges().Len() != 12 || len(g.Faces()) != 4 || len(r.events) != 0 {
+		t.Error("dcel: graph modified")
+	}
+	if len(g.BoundaryLoops()) != 2 {
+		t.Error("dcel: boundary loops modified")
+	}
+	checkLinks(t, g)
+
+	// The same bowties in two graphs. The copy of the second one is removed
+	// without notifying the observers.
+	other := New(nil)
+	for _, f := range [][]int64{{12, 11, 10}, {14, 13, 12}} {
+		if err := other.AddFace(other.NewFaceID(), NodeID(f[0]), NodeID(f[1]), NodeID(f[2])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	g.RemoveFace(g.Face(3))
+	g.RemoveFace(g.Face(2))
+	for _, id := range []int64{10, 11, 12, 13, 14} {
+		g.RemoveNode(id)
+	}
+	r.events = nil
+	nextNode, nextEdge, nextFace := g.nextNodeID, g.nextEdgeID, g.nextFaceID
+	if err := g.Stitch(g.Halfedge(1, 0), other.Halfedge(10, 11)); err == nil {
+		t.Fatal("dcel: expected error for faces of another graph that cannot be added back")
+	}
+	if g.Nodes().Len() != 5 || g.Edges().Len() != 6 || len(g.Faces()) != 2 || len(r.events) != 0 {
+		t.Error("dcel: graph modified by another graph")
+	}
+	if g.nextNodeID != nextNode || g.nextEdgeID != nextEdge || g.nextFaceID != nextFace {
+		t.Error("dcel: IDs of the removed copy not released")
+	}
+	checkLinks(t, g)
+}
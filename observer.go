@@ -0,0 +1,78 @@
+package dcel
+
+// Observer is notified of changes of the topology of a Graph. The methods are
+// called synchronously by the graph and must not modify it.
+//
+// Additions are reported after the element has been connected to the graph.
+// Removals are reported before the element is disconnected so that its
+// connections can still be inspected, and after any elements that are removed
+// as a consequence: the faces adjacent to a removed edge are reported before
+// the edge and the edges attached to a removed node are reported before the
+// node.
+type Observer interface {
+	// NodeAdded is called when a node is added to the graph.
+	NodeAdded(Node)
+	// NodeRemoved is called when a node is removed from the graph.
+	NodeRemoved(Node)
+
+	// EdgeAdded is called when an edge is added to the graph.
+	EdgeAdded(Edge)
+	// EdgeRemoved is called when an edge is removed from the graph.
+	EdgeRemoved(Edge)
+
+	// FaceAdded is called when a face is added to the graph.
+	FaceAdded(Face)
+	// FaceRemoved is called when a face is removed from the graph.
+	FaceRemoved(Face)
+	// FaceReversed is called when the orientation of a face has been
+	// reversed in place.
+	FaceReversed(Face)
+
+	// HalfedgesReconnected is called for each pair of halfedges around
+	// their common node that has been linked so that in.Next() is out. The
+	// links between the halfedges of an added edge can be read when
+	// EdgeAdded is called and the links of the halfedges around the nodes
+	// of an added or removed edge are reported after EdgeAdded or
+	// EdgeRemoved, so replaying the calls in order reproduces the links of
+	// the graph. A halfedge that has moved to another node, as when nodes
+	// are welded or an edge is collapsed, is reported with its incoming
+	// neighbor after the move, even if the link has not changed.
+	HalfedgesReconnected(in, out Halfedge)
+}
+
+// reconnected notifies the observers of g that in has been linked to out.
+func (g *Graph) reconnected(in, out Halfedge) {
+	for _, o := range g.observers {
+		o.HalfedgesReconnected(in, out)
+	}
+}
+
+// NopObserver is an Observer that ignores all changes. It can be embedded in
+// types that observe only some changes.
+type NopObserver struct{}
+
+func (NopObserver) NodeAdded(Node)                     {}
+func (NopObserver) NodeRemoved(Node)                   {}
+func (NopObserver) EdgeAdded(Edge)                     {}
+func (NopObserver) EdgeRemoved(Edge)                   {}
+func (NopObserver) FaceAdded(Face)                     {}
+func (NopObserver) FaceRemoved(Face)                   {}
+func (NopObserver) FaceReversed(Face)                  {}
+func (NopObserver) HalfedgesReconnected(_, _ Halfedge) {}
+
+// AddObserver registers o to be notified of changes of g. An observer added
+// more than once is notified more than once.
+func (g *Graph) AddObserver(o Observer) {
+	g.observers = append(g.observers, o)
+}
+
+// RemoveObserver unregisters o from g. If o has been added more than once,
+// only one registration is removed. o must be comparable.
+func (g *Graph) RemoveObserver(o Observer) {
+	for i, obs := range g.observers {
+		if obs == o {
+			g.observers = append(g.observers[:i], g.observers[i+1:]...)
+			return
+		}
+	}
+}
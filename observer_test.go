@@ -0,0 +1,184 @@
+package dcel
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// recorder is an Observer that records the changes of a graph.
+type recorder struct {
+	g      *Graph
+	events []string
+	// faceSizes are the numbers of halfedges around removed faces.
+	faceSizes []int
+	// reconnections is the number of reported reconnections and unlinked is
+	// the number of those whose halfedges are not adjacent.
+	reconnections, unlinked int
+}
+
+func (r *recorder) NodeAdded(u Node)   { r.record("+n", u.ID()) }
+func (r *recorder) NodeRemoved(u Node) { r.record("-n", u.ID()) }
+func (r *recorder) EdgeAdded(e Edge)   { r.record("+e", e.ID()) }
+func (r *recorder) EdgeRemoved(e Edge) { r.record("-e", e.ID()) }
+func (r *recorder) FaceAdded(f Face)   { r.record("+f", f.ID()) }
+func (r *recorder) FaceRemoved(f Face) {
+	r.record("-f", f.ID())
+	r.faceSizes = append(r.faceSizes, len(r.g.HalfedgesAround(f)))
+}
+func (r *recorder) FaceReversed(f Face) { r.record("~f", f.ID()) }
+func (r *recorder) HalfedgesReconnected(in, out Halfedge) {
+	r.reconnections++
+	if in.Next() != out || out.Prev() != in {
+		r.unlinked++
+	}
+}
+
+func (r *recorder) record(kind string, id int64) {
+	r.events = append(r.events, fmt.Sprint(kind, id))
+}
+
+func TestObserver(t *testing.T) {
+	g := New(nil)
+	r := &recorder{g: g}
+	g.AddObserver(r)
+
+	if err := g.AddFace(0, NodeID(0), NodeID(1), NodeID(2)); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"+n0", "+n1", "+e0", "+n2", "+e1", "+e2", "+f0"}
+	if !reflect.DeepEqual(r.events, want) {
+		t.Errorf("dcel: wrong events: got %v, want %v", r.events, want)
+	}
+	if err := g.AddFace(1, NodeID(2), NodeID(1), NodeID(3)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Removing an edge removes its faces first.
+	r.events = nil
+	g.RemoveEdge(1, 2)
+	want = []string{"-f0", "-f1", "-e1"}
+	if !reflect.DeepEqual(r.events, want) {
+		t.Errorf("dcel: wrong events: got %v, want %v", r.events, want)
+	}
+	if !reflect.DeepEqual(r.faceSizes, []int{3, 3}) {
+		t.Errorf("dcel: removed faces disconnected before notification: %v", r.faceSizes)
+	}
+
+	// Removing a node removes its edges first.
+	r.events = nil
+	g.RemoveNode(0)
+	want = []string{"-e0", "-e2", "-n0"}
+	if len(r.events) != 3 || r.events[2] != "-n0" {
+		t.Errorf("dcel: wrong events: got %v, want %v in any order of edges", r.events, want)
+	}
+
+	g.RemoveObserver(r)
+	r.events = nil
+	g.RemoveNode(1)
+	if len(r.events) != 0 {
+		t.Errorf("dcel: removed observer notified: %v", r.events)
+	}
+}
+
+func TestObserverReconnection(t *testing.T) {
+	g := New(nil)
+	r := &recorder{g: g}
+	g.AddObserver(NopObserver{})
+	g.AddObserver(r)
+
+	// Add the faces of a fan around node 0 in an order that requires
+	// relinking the halfedges around node 0.
+	for _, f := range [][]int64{{0, 4, 5}, {0, 7, 8}, {0, 3, 4}, {0, 2, 3}, {0, 5, 6}, {0, 6, 7}, {0, 8, 1}, {0, 1, 2}} {
+		if err := g.AddFace(g.NewFaceID(), NodeID(f[0]), NodeID(f[1]), NodeID(f[2])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if r.reconnections == 0 || r.unlinked != 0 {
+		t.Errorf("dcel: wrong reconnections: %d reported, %d not linked", r.reconnections, r.unlinked)
+	}
+	if len(g.BoundaryLoops()) != 1 {
+		t.Error("dcel: wrong boundary of fan")
+	}
+}
+
+// replayer is an Observer that maintains a copy of the links between the
+// halfedges of a graph from the reported changes.
+type replayer struct {
+	NopObserver
+	next map[Halfedge]Halfedge
+}
+
+func (r *replayer) EdgeAdded(e Edge) {
+	h1, h2 := e.Halfedges()
+	r.next[h1] = h1.Next()
+	r.next[h2] = h2.Next()
+}
+
+func (r *replayer) EdgeRemoved(e Edge) {
+	h1, h2 := e.Halfedges()
+	delete(r.next, h1)
+	delete(r.next, h2)
+}
+
+func (r *replayer) HalfedgesReconnected(in, out Halfedge) { r.next[in] = out }
+
+// check checks that the rotations of halfedges around the nodes of g given by
+// the replayed links are the rotations in g.
+func (r *replayer) check(t *testing.T, g *Graph) {
+	t.Helper()
+	if len(r.next) != 2*g.Edges().Len() {
+		t.Errorf("dcel: replayed links of %d halfedges, want %d", len(r.next), 2*g.Edges().Len())
+	}
+	for _, id := range g.nodeIDs() {
+		rotation := g.HalfedgesFrom(id)
+		for i, h := range rotation {
+			if got, want := r.next[h.Twin()], rotation[(i+1)%len(rotation)]; got != want {
+				t.Fatalf("dcel: wrong replayed rotation around node %d", id)
+			}
+		}
+	}
+}
+
+func TestObserverReplay(t *testing.T) {
+	g := New(nil)
+	r := &replayer{next: make(map[Halfedge]Halfedge)}
+	g.AddObserver(r)
+
+	// Add the faces of a fan around node 0 in an order that requires
+	// relinking the halfedges around node 0 and around the nodes on the
+	// rim.
+	for _, f := range [][]int64{{0, 4, 5}, {0, 7, 8}, {0, 2, 3}, {0, 5, 6}, {0, 3, 4}, {0, 8, 1}, {0, 6, 7}, {0, 1, 2}} {
+		if err := g.AddFace(g.NewFaceID(), NodeID(f[0]), NodeID(f[1]), NodeID(f[2])); err != nil {
+			t.Fatal(err)
+		}
+		r.check(t, g)
+	}
+	// Attach faces to the rim.
+	for _, f := range [][]int64{{2, 1, 9}, {4, 3, 10}, {3, 2, 11}, {9, 1, 12}} {
+		if err := g.AddFace(g.NewFaceID(), NodeID(f[0]), NodeID(f[1]), NodeID(f[2])); err != nil {
+			t.Fatal(err)
+		}
+		r.check(t, g)
+	}
+
+	g.RemoveEdge(0, 3)
+	r.check(t, g)
+	g.RemoveNode(2)
+	r.check(t, g)
+	g.RemoveEdge(0, 6)
+	r.check(t, g)
+	if err := g.AddFace(g.NewFaceID(), NodeID(0), NodeID(1), NodeID(2)); err != nil {
+		t.Fatal(err)
+	}
+	r.check(t, g)
+
+	// The face {4, 3, 10} touches the fan at node 4 and a dangling edge at
+	// node 3.
+	if err := g.ReverseFace(g.Halfedge(4, 3).Face()); err != nil {
+		t.Fatal(err)
+	}
+	r.check(t, g)
+	g.ReverseAll()
+	r.check(t, g)
+}
@@ -0,0 +1,104 @@
+package dcel
+
+import (
+	"sort"
+
+	"gonum.org/v1/gonum/graph"
+)
+
+// OrientConsistently reverses the order of nodes of faces in place so that
+// any two faces that share an edge traverse it in opposite directions, as
+// required by AddFace. Faces are connected through edges shared by exactly two
+// faces and in each connected set of faces the winding of the majority of
+// faces is kept.
+//
+// If the faces of a connected set cannot be oriented consistently, for example
+// because they form a Möbius strip, they are left unchanged. The indices of
+// faces in such sets are returned, one increasing slice for each set.
+func OrientConsistently(faces [][]graph.Node) [][]int {
+	// side is an occurrence of an edge in a face.
+	type side struct {
+		face int
+		// forward is whether the face traverses the edge from the node with
+		// the smaller ID.
+		forward bool
+	}
+	edges := make(map[[2]int64][]side)
+	for i, f := range faces {
+		for k, u := range f {
+			v := f[(k+1)%len(f)]
+			if u.ID() == v.ID() {
+				continue
+			}
+			key := edgeKey(u.ID(), v.ID())
+			edges[key] = append(edges[key], side{face: i, forward: u.ID() == key[0]})
+		}
+	}
+
+	var (
+		flip          = make([]bool, len(faces))
+		visited       = make([]bool, len(faces))
+		nonOrientable [][]int
+	)
+	for start := range faces {
+		if visited[start] {
+			continue
+		}
+		visited[start] = true
+		component := []int{start}
+		orientable := true
+		for q := 0; q < len(component); q++ {
+			i := component[q]
+			f := faces[i]
+			for k, u := range f {
+				v := f[(k+1)%len(f)]
+				key := edgeKey(u.ID(), v.ID())
+				sides := edges[key]
+				if len(sides) != 2 {
+					continue
+				}
+				// The other face must traverse the edge in the opposite
+				// direction.
+				want := (u.ID() == key[0]) == flip[i]
+				for _, s := range sides {
+					if s.face == i {
+						continue
+					}
+					if !visited[s.face] {
+						visited[s.face] = true
+						flip[s.face] = s.forward != want
+						component = append(component, s.face)
+					} else if (s.forward != flip[s.face]) != want {
+						orientable = false
+					}
+				}
+			}
+		}
+
+		if !orientable {
+			sort.Ints(component)
+			nonOrientable = append(nonOrientable, component)
+			continue
+		}
+		var flipped int
+		for _, i := range component {
+			if flip[i] {
+				flipped++
+			}
+		}
+		majority := 2*flipped > len(component)
+		for _, i := range component {
+			if flip[i] != majority {
+				reverse(faces[i])
+			}
+		}
+	}
+	return nonOrientable
+}
+
+// reverse reverses the order of nodes.
+func reverse(nodes []graph.Node) {
+	for i, j := 0, len(nodes)-1; i < j; i, j = i+1, j-1 {
+		nodes[i], nodes[j] = nodes[j], nodes[i]
+	}
+}
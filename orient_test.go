@@ -0,0 +1,78 @@
+package dcel
+
+import (
+	"reflect"
+	"testing"
+
+	"gonum.org/v1/gonum/graph"
+)
+
+// faceNodes returns the faces given by node IDs as slices of graph nodes.
+func faceNodes(faces [][]int64) [][]graph.Node {
+	nodes := make([][]graph.Node, len(faces))
+	for i, f := range faces {
+		for _, id := range f {
+			nodes[i] = append(nodes[i], NodeID(id))
+		}
+	}
+	return nodes
+}
+
+func TestOrientConsistently(t *testing.T) {
+	// A cube with two faces with the wrong winding.
+	faces := faceNodes([][]int64{
+		{0, 2, 3, 1}, {6, 7, 5, 4}, {0, 1, 5, 4}, {3, 7, 6, 2}, {0, 4, 6, 2}, {1, 3, 7, 5},
+	})
+	if bad := OrientConsistently(faces); bad != nil {
+		t.Fatalf("dcel: unexpected non-orientable faces: %v", bad)
+	}
+	if faces[0][0].ID() != 0 || faces[0][1].ID() != 2 {
+		t.Error("dcel: face with the majority winding reversed")
+	}
+	g := New(nil)
+	for i, f := range faces {
+		if err := g.AddFace(int64(i), f...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(g.BoundaryLoops()) != 0 {
+		t.Error("dcel: cube is not closed")
+	}
+
+	// Two strips of squares sharing no edge where the winding of most faces
+	// of the second strip is flipped.
+	faces = faceNodes([][]int64{
+		{0, 1, 4, 3}, {1, 2, 5, 4},
+		{14, 15, 11, 10}, {15, 16, 12, 11}, {12, 13, 17, 16},
+	})
+	want := [][]int64{{0, 1, 4, 3}, {1, 2, 5, 4}, {14, 15, 11, 10}, {15, 16, 12, 11}, {16, 17, 13, 12}}
+	if bad := OrientConsistently(faces); bad != nil {
+		t.Fatalf("dcel: unexpected non-orientable faces: %v", bad)
+	}
+	for i, f := range faces {
+		var ids []int64
+		for _, u := range f {
+			ids = append(ids, u.ID())
+		}
+		if !reflect.DeepEqual(ids, want[i]) {
+			t.Errorf("dcel: wrong face %d: got %v, want %v", i, ids, want[i])
+		}
+	}
+}
+
+func TestOrientConsistentlyMobius(t *testing.T) {
+	// A Möbius strip of squares with top nodes 0 to 4 and bottom nodes 5 to
+	// 9, and a separate triangle.
+	ids := [][]int64{
+		{0, 1, 6, 5}, {2, 7, 6, 1}, {2, 3, 8, 7}, {3, 4, 9, 8}, {4, 5, 0, 9},
+		{20, 21, 22},
+	}
+	faces := faceNodes(ids)
+	bad := OrientConsistently(faces)
+	if !reflect.DeepEqual(bad, [][]int{{0, 1, 2, 3, 4}}) {
+		t.Errorf("dcel: wrong non-orientable faces: %v", bad)
+	}
+	if !reflect.DeepEqual(faces, faceNodes(ids)) {
+		t.Error("dcel: non-orientable faces modified")
+	}
+}
@@ -0,0 +1,662 @@
+package dcel
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+
+	"gonum.org/v1/gonum/graph"
+)
+
+// OverlayFaces holds the faces of the two input graphs of Overlay that contain
+// a face of the overlay.
+type OverlayFaces struct {
+	// A is the face of the first graph or nil if the face of the overlay
+	// lies outside of the faces of the first graph.
+	A Face
+	// B is the face of the second graph or nil if the face of the overlay
+	// lies outside of the faces of the second graph.
+	B Face
+}
+
+// OverlayMapping maps the IDs of the faces of an overlay to the faces of the
+// input graphs that contain them.
+type OverlayMapping map[int64]OverlayFaces
+
+// Overlay returns the overlay of the planar subdivisions a and b whose nodes
+// carry positions in the XY plane. The nodes of the overlay are the nodes of
+// a and b and the intersections of their edges. The edges of the overlay are
+// the pieces of the edges of a and b between the nodes. Each face of the
+// overlay lies in the intersection of a face of a and a face of b, or of a
+// face of one graph and the outside of the other. The returned mapping records
+// these faces. Regions that lie outside of the faces of both a and b are not
+// faces of the overlay.
+//
+// Faces of a DCEL cannot have holes, so a region of the overlay that encloses
+// other parts of the overlay or whose boundary touches itself is split into
+// several faces by additional edges. All such faces are mapped to the same
+// faces of a and b.
+//
+// The edge intersections are found by a plane sweep in O((n + k) log n) time
+// for n edges with k intersections. The intersections are computed exactly and
+// edges that cross at the same point share one node of the overlay. Only the
+// positions of the nodes of the overlay are rounded to float64. The overlay is
+// allocated with the Items of a.
+//
+// The faces of a and b must be counterclockwise and the edges of a graph must
+// not cross each other. These conditions are only detected while the overlay is
+// built, so Overlay returns an error instead of panicking. An error is
+// returned if a node of a or b does not carry a position or if the regions of
+// the overlay cannot be built because a or b is not a planar subdivision.
+func Overlay(a, b *Graph) (*Graph, OverlayMapping, error) {
+	o := overlay{
+		in:    [2]*Graph{a, b},
+		index: make(map[sweepKey]int),
+		edges: make(map[[2]int]*overlayEdge),
+	}
+	segs, err := o.segments()
+	if err != nil {
+		return nil, nil, err
+	}
+	o.split(segs)
+	o.sortAdjacent()
+
+	cycles, cycleOf := o.cycles()
+	labels := make([][2]Face, len(cycles))
+	for i, c := range cycles {
+		labels[i] = o.label(c)
+	}
+	regions, err := o.regions(cycles, cycleOf, labels)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	g := New(a.items)
+	nodes := make([]graph.Node, len(o.points))
+	for i, p := range o.points {
+		nodes[i] = addPointNode(g, int64(i), p)
+	}
+	mapping := make(OverlayMapping)
+	for _, r := range regions {
+		pieces := [][]int{r.cycles[0]}
+		if len(r.cycles) > 1 || !distinct(r.cycles[0]) {
+			pieces, err = o.decompose(r.cycles)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		for _, piece := range pieces {
+			face := make([]graph.Node, len(piece))
+			for i, u := range piece {
+				face[i] = nodes[u]
+			}
+			id := g.NewFaceID()
+			if err := g.AddFace(id, face...); err != nil {
+				return nil, nil, err
+			}
+			mapping[id] = OverlayFaces{A: r.label[0], B: r.label[1]}
+		}
+	}
+	for _, key := range o.edgeKeys() {
+		if !g.HasEdgeBetween(int64(key[0]), int64(key[1])) {
+			if _, err := g.addEdge(nodes[key[0]], nodes[key[1]]); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	o.relink(g)
+
+	return g, mapping, nil
+}
+
+// overlay holds the intermediate state of the computation of an overlay. The
+// nodes of the overlay are identified by their index in points.
+type overlay struct {
+	in [2]*Graph
+
+	// exact holds the exact positions of the nodes and points holds their
+	// positions rounded to float64.
+	exact  []sweepPoint
+	points []Point
+	index  map[sweepKey]int
+
+	// edges holds the edges of the overlay keyed by the indices of their
+	// end nodes in increasing order.
+	edges map[[2]int]*overlayEdge
+	// adjacent holds the neighbors of each node in counterclockwise order.
+	adjacent [][]int
+	// rank holds the position of the edge from the first node to the second
+	// node in adjacent.
+	rank map[[2]int]int
+
+	locators [2]*Locator
+}
+
+// overlayEdge is an edge of the overlay.
+type overlayEdge struct {
+	// h holds for each input graph the halfedge that contains the edge and
+	// has the same direction as the edge or nil if no edge of the input graph
+	// contains the edge.
+	h [2]Halfedge
+}
+
+// overlaySegment is an edge of an input graph.
+type overlaySegment struct {
+	p, q int
+	// h is the halfedge of the input graph from p to q.
+	h Halfedge
+	// in is the index of the input graph.
+	in int
+	// a and b are the positions of the ends of the segment ordered from
+	// left to right and from bottom to top.
+	a, b Point
+	// splits are the nodes where the segment is split by the edges of the
+	// other graph.
+	splits []int
+}
+
+// regionCycles is a region of the overlay formed by a counterclockwise boundary
+// cycle followed by the cycles of the holes of the region.
+type regionCycles struct {
+	cycles [][]int
+	label  [2]Face
+}
+
+// node returns the index of the node at the exact position p, adding it if it
+// does not exist.
+func (o *overlay) node(p sweepPoint) int {
+	key := p.key()
+	i, ok := o.index[key]
+	if !ok {
+		i = len(o.points)
+		o.exact = append(o.exact, p)
+		o.points = append(o.points, p.p)
+		o.index[key] = i
+	}
+	return i
+}
+
+// segments adds the nodes of the input graphs and returns their edges.
+func (o *overlay) segments() ([]*overlaySegment, error) {
+	var segs []*overlaySegment
+	for in, g := range o.in {
+		for _, id := range g.nodeIDs() {
+			p, ok := position(g.nodes[id])
+			if !ok {
+				return nil, fmt.Errorf("dcel: node %d does not carry a position", id)
+			}
+			o.node(sweepPoint{p: Point{X: p.X, Y: p.Y}})
+		}
+		for _, id := range g.edgeIDs() {
+			h, t := g.edges[id].Halfedges()
+			p, q := point(h.From()), point(t.From())
+			p, q = Point{X: p.X, Y: p.Y}, Point{X: q.X, Y: q.Y}
+			s := &overlaySegment{
+				p:  o.node(sweepPoint{p: p}),
+				q:  o.node(sweepPoint{p: q}),
+				h:  h,
+				in: in,
+				a:  p,
+				b:  q,
+			}
+			if (sweepPoint{p: q}).less(sweepPoint{p: p}) {
+				s.a, s.b = q, p
+			}
+			segs = append(segs, s)
+		}
+	}
+	return segs, nil
+}
+
+// split finds the intersections between the segments of different input graphs
+// by a plane sweep and adds the pieces of the segments between the
+// intersections as edges of the overlay.
+//
+// The sweep keeps the segments that cross the sweep line ordered by Y and
+// tests only segments that become neighbors in this order for intersection.
+// The intersections are events of the sweep given by exact coordinates, so
+// that all segments through a point are split by the same node. For n
+// segments with k intersections, the running time is O((n + k) log n).
+func (o *overlay) split(segs []*overlaySegment) {
+	sw := sweep{events: make(map[sweepKey]*sweepEvent)}
+	for _, s := range segs {
+		if s.p == s.q {
+			// The segment has zero length, so it has no pieces.
+			continue
+		}
+		e := sw.event(sweepPoint{p: s.a})
+		e.start = append(e.start, s)
+		sw.event(sweepPoint{p: s.b})
+	}
+	sw.run(func(p sweepPoint, inner []*overlaySegment) {
+		u := o.node(p)
+		for _, s := range inner {
+			s.splits = append(s.splits, u)
+		}
+	})
+
+	o.adjacent = make([][]int, len(o.points))
+	for _, s := range segs {
+		// The splits lie on the segment, so their order along it is the
+		// order of the sweep from s.a to s.b.
+		forward := s.a == o.points[s.p]
+		sort.Slice(s.splits, func(i, j int) bool {
+			return o.exact[s.splits[i]].less(o.exact[s.splits[j]]) == forward
+		})
+		prev := s.p
+		for _, u := range append(s.splits, s.q) {
+			if u != prev {
+				o.addEdge(prev, u, s)
+				prev = u
+			}
+		}
+	}
+}
+
+// opposite returns whether x and y are non-zero and have opposite signs.
+func opposite(x, y float64) bool {
+	return (x < 0 && y > 0) || (x > 0 && y < 0)
+}
+
+// between returns whether r that is collinear with p and q lies strictly
+// between them.
+func between(p, q, r Point) bool {
+	if p.X != q.X {
+		return math.Min(p.X, q.X) < r.X && r.X < math.Max(p.X, q.X)
+	}
+	return math.Min(p.Y, q.Y) < r.Y && r.Y < math.Max(p.Y, q.Y)
+}
+
+// addEdge adds the edge from u to v that is a piece of the segment s.
+func (o *overlay) addEdge(u, v int, s *overlaySegment) {
+	key := [2]int{u, v}
+	h := s.h
+	if u > v {
+		key = [2]int{v, u}
+		h = h.Twin()
+	}
+	e, ok := o.edges[key]
+	if !ok {
+		e = &overlayEdge{}
+		o.edges[key] = e
+		o.adjacent[u] = append(o.adjacent[u], v)
+		o.adjacent[v] = append(o.adjacent[v], u)
+	}
+	e.h[s.in] = h
+}
+
+// edgeKeys returns the keys of the edges of the overlay in sorted order.
+func (o *overlay) edgeKeys() [][2]int {
+	keys := make([][2]int, 0, len(o.edges))
+	for key := range o.edges {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i][0] != keys[j][0] {
+			return keys[i][0] < keys[j][0]
+		}
+		return keys[i][1] < keys[j][1]
+	})
+	return keys
+}
+
+// sortAdjacent sorts the neighbors of each node in counterclockwise order.
+func (o *overlay) sortAdjacent() {
+	o.rank = make(map[[2]int]int)
+	for u, adj := range o.adjacent {
+		p := o.exact[u]
+		sort.Slice(adj, func(i, j int) bool { return ccwLess(p, o.exact[adj[i]], o.exact[adj[j]]) })
+		for i, v := range adj {
+			o.rank[[2]int{u, v}] = i
+		}
+	}
+}
+
+// ccwLess returns whether the direction from o to p precedes the direction from
+// o to q in counterclockwise order starting at the direction of the X axis.
+func ccwLess(o, p, q sweepPoint) bool {
+	upper := func(p sweepPoint) bool {
+		cx, cy := p.compare(o)
+		return cy > 0 || (cy == 0 && cx > 0)
+	}
+	if upper(p) != upper(q) {
+		return upper(p)
+	}
+	return orientSweep(o, p, q) > 0
+}
+
+// next returns the edge that follows the edge from u to v on the boundary of
+// the region on its left.
+func (o *overlay) next(u, v int) (int, int) {
+	adj := o.adjacent[v]
+	i := o.rank[[2]int{v, u}]
+	return v, adj[(i+len(adj)-1)%len(adj)]
+}
+
+// cycles returns the boundary cycles of the regions of the overlay as
+// sequences of nodes and the index of the cycle of each directed edge.
+func (o *overlay) cycles() ([][]int, map[[2]int]int) {
+	var cycles [][]int
+	cycleOf := make(map[[2]int]int)
+	for _, key := range o.edgeKeys() {
+		for _, d := range [][2]int{key, {key[1], key[0]}} {
+			if _, ok := cycleOf[d]; ok {
+				continue
+			}
+			var c []int
+			for u, v := d[0], d[1]; ; {
+				cycleOf[[2]int{u, v}] = len(cycles)
+				c = append(c, u)
+				u, v = o.next(u, v)
+				if u == d[0] && v == d[1] {
+					break
+				}
+			}
+			cycles = append(cycles, c)
+		}
+	}
+	return cycles, cycleOf
+}
+
+// label returns the faces of the input graphs that contain the region on the
+// left of the cycle c.
+func (o *overlay) label(c []int) [2]Face {
+	var (
+		label [2]Face
+		found [2]bool
+	)
+	for i, u := range c {
+		v := c[(i+1)%len(c)]
+		key := [2]int{u, v}
+		if u > v {
+			key = [2]int{v, u}
+		}
+		for in, h := range o.edges[key].h {
+			if h == nil || found[in] {
+				continue
+			}
+			if u > v {
+				h = h.Twin()
+			}
+			label[in] = h.Face()
+			found[in] = true
+		}
+	}
+
+	// The cycle does not contain edges of an input graph, so the region lies
+	// in a single face of that graph or outside of its faces. The midpoint
+	// of an edge of the cycle does not lie on an edge of the graph.
+	for in, ok := range found {
+		if ok {
+			continue
+		}
+		if o.locators[in] == nil {
+			// The nodes of the input graphs have been checked to carry
+			// positions, so NewLocator cannot fail.
+			o.locators[in], _ = NewLocator(o.in[in])
+		}
+		p, q := o.points[c[0]], o.points[c[1]]
+		label[in], _, _ = o.locators[in].Locate((p.X+q.X)/2, (p.Y+q.Y)/2)
+	}
+	return label
+}
+
+// regions groups the cycles of the overlay into regions. Cycles that do not
+// have a positive area are the outer boundaries of parts of the overlay and
+// they are assigned as holes to the region that encloses them.
+func (o *overlay) regions(cycles [][]int, cycleOf map[[2]int]int, labels [][2]Face) ([]regionCycles, error) {
+	var (
+		regions  []regionCycles
+		regionOf = make(map[int]int)
+	)
+	for i, c := range cycles {
+		if labels[i] == [2]Face{} || o.area(c) <= 0 {
+			continue
+		}
+		regionOf[i] = len(regions)
+		regions = append(regions, regionCycles{cycles: [][]int{c}, label: labels[i]})
+	}
+	for i, c := range cycles {
+		if labels[i] == [2]Face{} || o.area(c) > 0 {
+			continue
+		}
+		// Follow the enclosing cycles until a region is found.
+		j := i
+		for k := 0; k < len(cycles); k++ {
+			d, ok := o.enclosing(cycles[j])
+			if !ok {
+				break
+			}
+			j = cycleOf[d]
+			if _, ok := regionOf[j]; ok || o.area(cycles[j]) > 0 {
+				break
+			}
+		}
+		r, ok := regionOf[j]
+		if !ok {
+			return nil, errors.New("dcel: cannot find region enclosing a part of the overlay")
+		}
+		regions[r].cycles = append(regions[r].cycles, c)
+	}
+	return regions, nil
+}
+
+// enclosing returns a directed edge that has the cycle c on its left and that
+// is the nearest edge hit by a ray from the leftmost node of c in the negative
+// direction of the X axis. If the ray does not hit any edge, enclosing returns
+// false.
+func (o *overlay) enclosing(c []int) ([2]int, bool) {
+	u := c[0]
+	for _, v := range c {
+		p, q := o.points[v], o.points[u]
+		if p.X < q.X || (p.X == q.X && p.Y < q.Y) {
+			u = v
+		}
+	}
+	p := o.points[u]
+
+	var (
+		hitX = math.Inf(-1)
+		hit  [2]int
+		ok   bool
+	)
+	for v, q := range o.points {
+		if q.Y != p.Y || q.X >= p.X || q.X <= hitX || len(o.adjacent[v]) == 0 {
+			continue
+		}
+		// Find the wedge around v that faces p.
+		adj := o.adjacent[v]
+		w := adj[0]
+		for i, w1 := range adj {
+			w2 := adj[(i+1)%len(adj)]
+			if w1 == w2 || inWedge(q, o.points[w1], o.points[w2], p) {
+				w = w1
+				break
+			}
+		}
+		hitX, hit, ok = q.X, [2]int{v, w}, true
+	}
+	for key := range o.edges {
+		a, b := o.points[key[0]], o.points[key[1]]
+		d := [2]int{key[1], key[0]}
+		if a.Y > b.Y {
+			a, b = b, a
+			d = key
+		}
+		if !(a.Y < p.Y && p.Y < b.Y) || orient(a, b, p) >= 0 {
+			continue
+		}
+		x := a.X + (p.Y-a.Y)*(b.X-a.X)/(b.Y-a.Y)
+		if x > hitX {
+			hitX, hit, ok = x, d, true
+		}
+	}
+	return hit, ok
+}
+
+// area returns the signed area of the polygon formed by the nodes in c. If a
+// node of c is an intersection whose position is not exact in float64, the
+// area is computed from the exact positions and only its sign is returned, so
+// that the orientation of cycles through such nodes is exact.
+func (o *overlay) area(c []int) float64 {
+	pts := make([]Point, len(c))
+	exact := true
+	for i, u := range c {
+		pts[i] = o.points[u]
+		exact = exact && o.exact[u].x == nil
+	}
+	if exact {
+		return area(pts)
+	}
+	sum := new(big.Rat)
+	for i, u := range c {
+		px, py := o.exact[u].coords()
+		qx, qy := o.exact[c[(i+1)%len(c)]].coords()
+		sum.Add(sum, new(big.Rat).Mul(px, qy))
+		sum.Sub(sum, new(big.Rat).Mul(py, qx))
+	}
+	return float64(sum.Sign())
+}
+
+// decompose splits the region bounded by cycles into polygons with distinct
+// nodes. The region is triangulated by a constrained Delaunay triangulation
+// and the triangles are greedily merged into larger polygons.
+func (o *overlay) decompose(cycles [][]int) ([][]int, error) {
+	local := make(map[int]int64)
+	var (
+		ids []int
+		pts []Point
+	)
+	for _, c := range cycles {
+		for _, u := range c {
+			if _, ok := local[u]; !ok {
+				local[u] = int64(len(pts))
+				ids = append(ids, u)
+				pts = append(pts, o.points[u])
+			}
+		}
+	}
+	tri, err := NewDelaunay(nil, pts)
+	if err != nil {
+		return nil, err
+	}
+	tg := tri.Graph()
+	for _, c := range cycles {
+		for i, u := range c {
+			v := c[(i+1)%len(c)]
+			if err := tri.InsertConstraint(tg.nodes[local[u]], tg.nodes[local[v]]); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Find the triangles inside the region.
+	inside := make(map[Face]bool)
+	var queue []Face
+	for _, c := range cycles {
+		for i, u := range c {
+			h := tg.Halfedge(local[u], local[c[(i+1)%len(c)]])
+			if h == nil {
+				return nil, errors.New("dcel: cannot triangulate region of the overlay")
+			}
+			if f := h.Face(); f != nil && !inside[f] {
+				inside[f] = true
+				queue = append(queue, f)
+			}
+		}
+	}
+	for len(queue) > 0 {
+		f := queue[0]
+		queue = queue[1:]
+		for _, h := range tg.HalfedgesAround(f) {
+			if tri.isConstrained(h) {
+				continue
+			}
+			if g := h.Twin().Face(); g != nil && !inside[g] {
+				inside[g] = true
+				queue = append(queue, g)
+			}
+		}
+	}
+
+	// Merge the triangles into polygons. A triangle can be merged with a
+	// polygon across an edge if its third node is not a node of the polygon.
+	var pieces [][]int
+	merged := make(map[Face]bool)
+	for _, id := range tg.faceIDs() {
+		f := tg.faces[id]
+		if !inside[f] || merged[f] {
+			continue
+		}
+		merged[f] = true
+		stack := tg.HalfedgesAround(f)
+		var poly []int64
+		nodes := make(map[int64]bool)
+		for _, h := range stack {
+			poly = append(poly, h.From().ID())
+			nodes[h.From().ID()] = true
+		}
+		for len(stack) > 0 {
+			h := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			t := h.Twin()
+			g := t.Face()
+			if g == nil || !inside[g] || merged[g] || tri.isConstrained(h) {
+				continue
+			}
+			z := t.Prev().From().ID()
+			if nodes[z] {
+				continue
+			}
+			for i, u := range poly {
+				if u == h.From().ID() {
+					poly = append(poly[:i+1], append([]int64{z}, poly[i+1:]...)...)
+					break
+				}
+			}
+			nodes[z] = true
+			merged[g] = true
+			stack = append(stack, t.Next(), t.Prev())
+		}
+		piece := make([]int, len(poly))
+		for i, u := range poly {
+			piece[i] = ids[u]
+		}
+		pieces = append(pieces, piece)
+	}
+	return pieces, nil
+}
+
+// distinct returns whether the nodes in c are distinct.
+func distinct(c []int) bool {
+	seen := make(map[int]bool)
+	for _, u := range c {
+		if seen[u] {
+			return false
+		}
+		seen[u] = true
+	}
+	return true
+}
+
+// relink links the free halfedges around each node of the overlay g so that
+// they follow the counterclockwise order of the exact positions of the nodes.
+func (o *overlay) relink(g *Graph) {
+	for _, id := range g.nodeIDs() {
+		hedges := g.HalfedgesFrom(id)
+		p := o.exact[id]
+		sort.Slice(hedges, func(i, j int) bool {
+			return ccwLess(p, o.exact[hedges[i].Twin().From().ID()], o.exact[hedges[j].Twin().From().ID()])
+		})
+		for i, h := range hedges {
+			if h.Face() != nil {
+				continue
+			}
+			in := hedges[(i+1)%len(hedges)].Twin()
+			in.SetNext(h)
+			h.SetPrev(in)
+		}
+	}
+}
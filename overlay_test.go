@@ -0,0 +1,266 @@
+package dcel
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"gonum.org/v1/gonum/graph"
+)
+
+// newPolygons returns a graph with a counterclockwise face for each of the
+// polygons. Vertices at equal positions share a node.
+func newPolygons(polys ...[]Point) *Graph {
+	g := New(PointBase{})
+	nodes := make(map[Point]Node)
+	for _, poly := range polys {
+		var face []graph.Node
+		for _, p := range poly {
+			u, ok := nodes[p]
+			if !ok {
+				u = addPointNode(g, g.NewNodeID(), p)
+				nodes[p] = u
+			}
+			face = append(face, u)
+		}
+		if err := g.AddFace(g.NewFaceID(), face...); err != nil {
+			panic(err)
+		}
+	}
+	return g
+}
+
+// rect returns the corners of a rectangle in counterclockwise order.
+func rect(x0, y0, x1, y1 float64) []Point {
+	return []Point{{X: x0, Y: y0}, {X: x1, Y: y0}, {X: x1, Y: y1}, {X: x0, Y: y1}}
+}
+
+// faceArea returns the area of the face f whose nodes carry positions.
+func faceArea(g *Graph, f Face) float64 {
+	var pts []Point
+	for _, h := range g.HalfedgesAround(f) {
+		pts = append(pts, point(h.From()))
+	}
+	return area(pts)
+}
+
+// checkOverlay checks that the faces of the overlay are counterclockwise and
+// that random points are located in faces mapped to the faces of a and b that
+// contain the points.
+func checkOverlay(t *testing.T, a, b, g *Graph, mapping OverlayMapping, rnd *rand.Rand, box Box) {
+	t.Helper()
+	if len(mapping) != len(g.Faces()) {
+		t.Errorf("dcel: wrong size of mapping: got %d, want %d", len(mapping), len(g.Faces()))
+	}
+	for _, f := range g.Faces() {
+		if faceArea(g, f) <= 0 {
+			t.Errorf("dcel: face %d is not counterclockwise", f.ID())
+		}
+	}
+	la, _ := NewLocator(a)
+	lb, _ := NewLocator(b)
+	l, err := NewLocator(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k := 0; k < 1000; k++ {
+		x := box.Min.X + rnd.Float64()*(box.Max.X-box.Min.X)
+		y := box.Min.Y + rnd.Float64()*(box.Max.Y-box.Min.Y)
+		fa, _, _ := la.Locate(x, y)
+		fb, _, _ := lb.Locate(x, y)
+		f, _, _ := l.Locate(x, y)
+		if f == nil {
+			if fa != nil || fb != nil {
+				t.Errorf("dcel: point (%v, %v) not in overlay", x, y)
+			}
+			continue
+		}
+		if m := mapping[f.ID()]; m.A != fa || m.B != fb {
+			t.Errorf("dcel: wrong mapping of face %d at (%v, %v)", f.ID(), x, y)
+		}
+	}
+}
+
+func TestOverlay(t *testing.T) {
+	a := newPolygons(rect(0, 0, 2, 2))
+	b := newPolygons(rect(1, 1, 3, 3))
+	g, mapping, err := Overlay(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fa, fb := a.Face(0), b.Face(0)
+	areas := make(map[OverlayFaces]float64)
+	for _, f := range g.Faces() {
+		areas[mapping[f.ID()]] += faceArea(g, f)
+	}
+	want := map[OverlayFaces]float64{
+		{A: fa, B: fb}: 1,
+		{A: fa}:        3,
+		{B: fb}:        3,
+	}
+	if len(areas) != len(want) {
+		t.Errorf("dcel: wrong faces of overlay: %v", areas)
+	}
+	for m, w := range want {
+		if areas[m] != w {
+			t.Errorf("dcel: wrong area of %v: got %v, want %v", m, areas[m], w)
+		}
+	}
+	if g.Nodes().Len() != 10 || len(g.Faces()) != 3 {
+		t.Errorf("dcel: wrong size of overlay: %d nodes, %d faces", g.Nodes().Len(), len(g.Faces()))
+	}
+	checkOverlay(t, a, b, g, mapping, rand.New(rand.NewPCG(1, 1)), Box{Min: Point{X: -1, Y: -1}, Max: Point{X: 4, Y: 4}})
+}
+
+func TestOverlayHoles(t *testing.T) {
+	// b lies inside a and touches itself, so the region of a outside of b
+	// has a hole whose boundary has a repeated node.
+	a := newPolygons(rect(0, 0, 10, 10))
+	b := newPolygons(rect(2, 2, 4, 4), rect(4, 4, 6, 6), rect(6, 2, 8, 3))
+	g, mapping, err := Overlay(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	areas := make(map[OverlayFaces]float64)
+	for _, f := range g.Faces() {
+		areas[mapping[f.ID()]] += faceArea(g, f)
+	}
+	if got := areas[OverlayFaces{A: a.Face(0)}]; math.Abs(got-90) > 1e-12 {
+		t.Errorf("dcel: wrong area of a outside of b: got %v, want 90", got)
+	}
+	topo := g.Topology()
+	if len(topo.Components) != 1 || !topo.Components[0].Manifold || topo.EulerCharacteristic != 1 {
+		t.Errorf("dcel: overlay is not a disk: %+v", topo)
+	}
+	checkOverlay(t, a, b, g, mapping, rand.New(rand.NewPCG(1, 2)), Box{Min: Point{X: -1, Y: -1}, Max: Point{X: 11, Y: 11}})
+
+	// The faces of b are disjoint from a.
+	a = newPolygons(rect(0, 0, 1, 1))
+	g, mapping, err = Overlay(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Faces()) != 4 || g.Topology().BoundaryLoops != 3 {
+		t.Errorf("dcel: wrong overlay of disjoint graphs")
+	}
+	checkOverlay(t, a, b, g, mapping, rand.New(rand.NewPCG(1, 3)), Box{Min: Point{X: -1, Y: -1}, Max: Point{X: 11, Y: 11}})
+}
+
+func TestOverlayVoronoi(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(1, 6))
+	sites := func(n int) []Point {
+		pts := make([]Point, n)
+		for i := range pts {
+			pts[i] = Point{X: rnd.Float64(), Y: rnd.Float64()}
+		}
+		return pts
+	}
+	a, err := Voronoi(nil, sites(50), Box{Max: Point{X: 0.7, Y: 0.8}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Voronoi(nil, sites(50), Box{Min: Point{X: 0.2, Y: 0.3}, Max: Point{X: 1, Y: 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, mapping, err := Overlay(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var total float64
+	for _, f := range g.Faces() {
+		total += faceArea(g, f)
+	}
+	if want := 0.7*0.8 + 0.8*0.7 - 0.5*0.5; math.Abs(total-want) > 1e-9 {
+		t.Errorf("dcel: wrong area of overlay: got %v, want %v", total, want)
+	}
+	checkOverlay(t, a, b, g, mapping, rnd, Box{Max: Point{X: 1, Y: 1}})
+}
+
+func TestOverlayDegenerate(t *testing.T) {
+	// The nodes lie on an integer grid, so edges of the two graphs overlap,
+	// meet at nodes of the other graph and several edges of one graph cross
+	// an edge of the other graph at their common node.
+	rnd := rand.New(rand.NewPCG(1, 7))
+	sites := func(n int) []Point {
+		seen := make(map[Point]bool)
+		var pts []Point
+		for len(pts) < n {
+			p := Point{X: float64(rnd.IntN(8)), Y: float64(rnd.IntN(8))}
+			if !seen[p] {
+				seen[p] = true
+				pts = append(pts, p)
+			}
+		}
+		return pts
+	}
+	ta, err := NewDelaunay(PointBase{}, sites(30))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tb, err := NewDelaunay(PointBase{}, sites(30))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, b := ta.Graph(), tb.Graph()
+	g, mapping, err := Overlay(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes := graph.NodesOf(g.Nodes())
+	for i, u := range nodes {
+		for _, v := range nodes[i+1:] {
+			if d := dist(point(u.(Node)), point(v.(Node))); d < 1e-9 {
+				t.Errorf("dcel: nodes %d and %d of overlay are %v apart", u.ID(), v.ID(), d)
+			}
+		}
+	}
+	for _, u := range nodes {
+		if len(g.HalfedgesFrom(u.ID())) == 0 {
+			t.Errorf("dcel: node %d of overlay is isolated", u.ID())
+		}
+	}
+	checkOverlay(t, a, b, g, mapping, rnd, Box{Min: Point{X: -1, Y: -1}, Max: Point{X: 8, Y: 8}})
+}
+
+func TestOverlayRounding(t *testing.T) {
+	// The node (1, f) of a lies just below the edge of a from (0, 0) to
+	// (3, 1), which the left edge of b crosses at (1, 1/3). The crossing
+	// rounds to the position of the node but it is a different point.
+	f := 1.0 / 3
+	a := newPolygons([]Point{{X: 0, Y: 0}, {X: 1, Y: f}, {X: 3, Y: 1}})
+	b := newPolygons(rect(1, -1, 2, 2))
+	g, mapping, err := Overlay(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var at []int64
+	for _, u := range graph.NodesOf(g.Nodes()) {
+		if point(u.(Node)) == (Point{X: 1, Y: f}) {
+			at = append(at, u.ID())
+		}
+	}
+	if len(at) != 2 {
+		t.Fatalf("dcel: want node and crossing at (1, 1/3), got %d nodes", len(at))
+	}
+	if !g.HasEdgeBetween(at[0], at[1]) {
+		t.Error("dcel: node and crossing at (1, 1/3) not connected by the edge of b")
+	}
+	// a is split by b into a sliver left of b, a piece inside b and a
+	// triangle right of b, and b is split by a into two pieces.
+	var count [2][2]int
+	for _, m := range mapping {
+		count[btoi(m.A != nil)][btoi(m.B != nil)]++
+	}
+	if count != [2][2]int{{0, 2}, {2, 1}} {
+		t.Errorf("dcel: wrong faces of overlay: %v", count)
+	}
+	checkLinks(t, g)
+}
+
+func btoi(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,119 @@
+package dcel
+
+import (
+	"math"
+	"math/big"
+)
+
+// Error bounds of the floating-point filters of the geometric predicates from
+// J. R. Shewchuk, Adaptive Precision Floating-Point Arithmetic and Fast Robust
+// Geometric Predicates, Discrete & Computational Geometry 18 (1997).
+const (
+	epsilon      = 1.0 / (1 << 53)
+	ccwErrBoundA = (3 + 16*epsilon) * epsilon
+	iccErrBoundA = (10 + 96*epsilon) * epsilon
+)
+
+// orient returns a positive value if the points a, b and c in the XY plane
+// are in counterclockwise order, a negative value if they are in clockwise
+// order, and zero if they are collinear. The sign of the result is exact.
+func orient(a, b, c Point) float64 {
+	detLeft := (a.X - c.X) * (b.Y - c.Y)
+	detRight := (a.Y - c.Y) * (b.X - c.X)
+	det := detLeft - detRight
+
+	var detSum float64
+	switch {
+	case detLeft > 0:
+		if detRight <= 0 {
+			return det
+		}
+		detSum = detLeft + detRight
+	case detLeft < 0:
+		if detRight >= 0 {
+			return det
+		}
+		detSum = -detLeft - detRight
+	default:
+		return det
+	}
+	if math.Abs(det) >= ccwErrBoundA*detSum {
+		return det
+	}
+	return orientExact(a, b, c)
+}
+
+func orientExact(a, b, c Point) float64 {
+	ax, ay := rat(a.X), rat(a.Y)
+	bx, by := rat(b.X), rat(b.Y)
+	cx, cy := rat(c.X), rat(c.Y)
+
+	acx := new(big.Rat).Sub(ax, cx)
+	bcy := new(big.Rat).Sub(by, cy)
+	acy := new(big.Rat).Sub(ay, cy)
+	bcx := new(big.Rat).Sub(bx, cx)
+
+	det := new(big.Rat).Mul(acx, bcy)
+	det.Sub(det, new(big.Rat).Mul(acy, bcx))
+	return float64(det.Sign())
+}
+
+// inCircle returns a positive value if the point d lies inside the circle
+// passing through the points a, b and c in the XY plane, a negative value if
+// it lies outside, and zero if the four points are cocircular. The points a, b
+// and c must be in counterclockwise order, otherwise the sign of the result is
+// reversed. The sign of the result is exact.
+func inCircle(a, b, c, d Point) float64 {
+	adx, ady := a.X-d.X, a.Y-d.Y
+	bdx, bdy := b.X-d.X, b.Y-d.Y
+	cdx, cdy := c.X-d.X, c.Y-d.Y
+
+	bdxcdy := bdx * cdy
+	cdxbdy := cdx * bdy
+	aLift := adx*adx + ady*ady
+
+	cdxady := cdx * ady
+	adxcdy := adx * cdy
+	bLift := bdx*bdx + bdy*bdy
+
+	adxbdy := adx * bdy
+	bdxady := bdx * ady
+	cLift := cdx*cdx + cdy*cdy
+
+	det := aLift*(bdxcdy-cdxbdy) + bLift*(cdxady-adxcdy) + cLift*(adxbdy-bdxady)
+	permanent := (math.Abs(bdxcdy)+math.Abs(cdxbdy))*aLift +
+		(math.Abs(cdxady)+math.Abs(adxcdy))*bLift +
+		(math.Abs(adxbdy)+math.Abs(bdxady))*cLift
+	if math.Abs(det) > iccErrBoundA*permanent {
+		return det
+	}
+	return inCircleExact(a, b, c, d)
+}
+
+func inCircleExact(a, b, c, d Point) float64 {
+	dx, dy := rat(d.X), rat(d.Y)
+	lift := func(p Point) (x, y, l *big.Rat) {
+		x = new(big.Rat).Sub(rat(p.X), dx)
+		y = new(big.Rat).Sub(rat(p.Y), dy)
+		l = new(big.Rat).Mul(x, x)
+		l.Add(l, new(big.Rat).Mul(y, y))
+		return x, y, l
+	}
+	adx, ady, aLift := lift(a)
+	bdx, bdy, bLift := lift(b)
+	cdx, cdy, cLift := lift(c)
+
+	// cross returns px*qy - py*qx.
+	cross := func(px, py, qx, qy *big.Rat) *big.Rat {
+		r := new(big.Rat).Mul(px, qy)
+		return r.Sub(r, new(big.Rat).Mul(py, qx))
+	}
+	det := new(big.Rat).Mul(aLift, cross(bdx, bdy, cdx, cdy))
+	det.Add(det, new(big.Rat).Mul(bLift, cross(cdx, cdy, adx, ady)))
+	det.Add(det, new(big.Rat).Mul(cLift, cross(adx, ady, bdx, bdy)))
+	return float64(det.Sign())
+}
+
+func rat(x float64) *big.Rat {
+	return new(big.Rat).SetFloat64(x)
+}
@@ -0,0 +1,323 @@
+package dcel
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// NodeProperty is a named attribute of type T of the nodes of a graph. The
+// value of a node is deleted when the node is removed from the graph.
+type NodeProperty[T any] struct{ *values[int64, T] }
+
+// AddNodeProperty registers a new node property with the given name in g and
+// returns it. If g already has a node property with the name, an error is
+// returned.
+func AddNodeProperty[T any](g *Graph, name string) (*NodeProperty[T], error) {
+	p := &NodeProperty[T]{newValues[int64, T](name)}
+	if err := register(&g.props.nodes, "node", name, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// FindNodeProperty returns the node property with the given name and type
+// registered in g.
+func FindNodeProperty[T any](g *Graph, name string) (*NodeProperty[T], bool) {
+	p, ok := g.props.nodes[name].(*NodeProperty[T])
+	return p, ok
+}
+
+// RemoveNodeProperty unregisters the node property with the given name from g.
+// The property keeps its values but they are no longer deleted with nodes.
+func (g *Graph) RemoveNodeProperty(name string) { delete(g.props.nodes, name) }
+
+// Get returns the value of u or the zero value if u has none.
+func (p *NodeProperty[T]) Get(u Node) T { return p.m[u.ID()] }
+
+// Set sets the value of u.
+func (p *NodeProperty[T]) Set(u Node, v T) { p.m[u.ID()] = v }
+
+// Delete deletes the value of u.
+func (p *NodeProperty[T]) Delete(u Node) { delete(p.m, u.ID()) }
+
+func (p *NodeProperty[T]) clone() property {
+	return &NodeProperty[T]{newValues[int64, T](p.name)}
+}
+
+// EdgeProperty is a named attribute of type T of the edges of a graph. The
+// value of an edge is deleted when the edge is removed from the graph.
+type EdgeProperty[T any] struct{ *values[int64, T] }
+
+// AddEdgeProperty registers a new edge property with the given name in g and
+// returns it. If g already has an edge property with the name, an error is
+// returned.
+func AddEdgeProperty[T any](g *Graph, name string) (*EdgeProperty[T], error) {
+	p := &EdgeProperty[T]{newValues[int64, T](name)}
+	if err := register(&g.props.edges, "edge", name, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// FindEdgeProperty returns the edge property with the given name and type
+// registered in g.
+func FindEdgeProperty[T any](g *Graph, name string) (*EdgeProperty[T], bool) {
+	p, ok := g.props.edges[name].(*EdgeProperty[T])
+	return p, ok
+}
+
+// RemoveEdgeProperty unregisters the edge property with the given name from g.
+// The property keeps its values but they are no longer deleted with edges.
+func (g *Graph) RemoveEdgeProperty(name string) { delete(g.props.edges, name) }
+
+// Get returns the value of e or the zero value if e has none.
+func (p *EdgeProperty[T]) Get(e Edge) T { return p.m[e.ID()] }
+
+// Set sets the value of e.
+func (p *EdgeProperty[T]) Set(e Edge, v T) { p.m[e.ID()] = v }
+
+// Delete deletes the value of e.
+func (p *EdgeProperty[T]) Delete(e Edge) { delete(p.m, e.ID()) }
+
+func (p *EdgeProperty[T]) clone() property {
+	return &EdgeProperty[T]{newValues[int64, T](p.name)}
+}
+
+// FaceProperty is a named attribute of type T of the faces of a graph. The
+// value of a face is deleted when the face is removed from the graph.
+type FaceProperty[T any] struct{ *values[int64, T] }
+
+// AddFaceProperty registers a new face property with the given name in g and
+// returns it. If g already has a face property with the name, an error is
+// returned.
+func AddFaceProperty[T any](g *Graph, name string) (*FaceProperty[T], error) {
+	p := &FaceProperty[T]{newValues[int64, T](name)}
+	if err := register(&g.props.faces, "face", name, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// FindFaceProperty returns the face property with the given name and type
+// registered in g.
+func FindFaceProperty[T any](g *Graph, name string) (*FaceProperty[T], bool) {
+	p, ok := g.props.faces[name].(*FaceProperty[T])
+	return p, ok
+}
+
+// RemoveFaceProperty unregisters the face property with the given name from g.
+// The property keeps its values but they are no longer deleted with faces.
+func (g *Graph) RemoveFaceProperty(name string) { delete(g.props.faces, name) }
+
+// Get returns the value of f or the zero value if f has none.
+func (p *FaceProperty[T]) Get(f Face) T { return p.m[f.ID()] }
+
+// Set sets the value of f.
+func (p *FaceProperty[T]) Set(f Face, v T) { p.m[f.ID()] = v }
+
+// Delete deletes the value of f.
+func (p *FaceProperty[T]) Delete(f Face) { delete(p.m, f.ID()) }
+
+func (p *FaceProperty[T]) clone() property {
+	return &FaceProperty[T]{newValues[int64, T](p.name)}
+}
+
+// HalfedgeProperty is a named attribute of type T of the halfedges of a graph.
+// The values of halfedges are deleted when their edge is removed from the
+// graph.
+type HalfedgeProperty[T any] struct{ *values[Halfedge, T] }
+
+// AddHalfedgeProperty registers a new halfedge property with the given name in
+// g and returns it. If g already has a halfedge property with the name, an
+// error is returned.
+func AddHalfedgeProperty[T any](g *Graph, name string) (*HalfedgeProperty[T], error) {
+	p := &HalfedgeProperty[T]{newValues[Halfedge, T](name)}
+	if err := register(&g.props.halfedges, "halfedge", name, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// FindHalfedgeProperty returns the halfedge property with the given name and
+// type registered in g.
+func FindHalfedgeProperty[T any](g *Graph, name string) (*HalfedgeProperty[T], bool) {
+	p, ok := g.props.halfedges[name].(*HalfedgeProperty[T])
+	return p, ok
+}
+
+// RemoveHalfedgeProperty unregisters the halfedge property with the given
+// name from g. The property keeps its values but they are no longer deleted
+// with edges.
+func (g *Graph) RemoveHalfedgeProperty(name string) { delete(g.props.halfedges, name) }
+
+// Get returns the value of h or the zero value if h has none.
+func (p *HalfedgeProperty[T]) Get(h Halfedge) T { return p.m[h] }
+
+// Set sets the value of h.
+func (p *HalfedgeProperty[T]) Set(h Halfedge, v T) { p.m[h] = v }
+
+// Delete deletes the value of h.
+func (p *HalfedgeProperty[T]) Delete(h Halfedge) { delete(p.m, h) }
+
+func (p *HalfedgeProperty[T]) clone() property {
+	return &HalfedgeProperty[T]{newValues[Halfedge, T](p.name)}
+}
+
+// values stores the values of a property keyed by element IDs or halfedges.
+type values[K comparable, T any] struct {
+	name string
+	m    map[K]T
+}
+
+func newValues[K comparable, T any](name string) *values[K, T] {
+	return &values[K, T]{name: name, m: make(map[K]T)}
+}
+
+// Name returns the name of the property.
+func (v *values[K, T]) Name() string { return v.name }
+
+// Len returns the number of elements that have a value.
+func (v *values[K, T]) Len() int { return len(v.m) }
+
+func (v *values[K, T]) value(key any) (any, bool) {
+	x, ok := v.m[key.(K)]
+	return x, ok
+}
+
+func (v *values[K, T]) setValue(key, x any) { v.m[key.(K)] = x.(T) }
+
+func (v *values[K, T]) remove(key any) { delete(v.m, key.(K)) }
+
+func (v *values[K, T]) each(fn func(key, x any)) {
+	for key, x := range v.m {
+		fn(key, x)
+	}
+}
+
+// property is the untyped interface of the values of a property.
+type property interface {
+	value(key any) (any, bool)
+	setValue(key, x any)
+	remove(key any)
+	each(fn func(key, x any))
+	// clone returns a new property of the same kind, name and type without
+	// values.
+	clone() property
+}
+
+// properties is the registry of the properties of a graph.
+type properties struct {
+	nodes     map[string]property
+	edges     map[string]property
+	faces     map[string]property
+	halfedges map[string]property
+}
+
+// register adds p to the registry reg of properties of the given kind of
+// elements.
+func register(reg *map[string]property, kind, name string, p property) error {
+	if *reg == nil {
+		*reg = make(map[string]property)
+	}
+	if _, exists := (*reg)[name]; exists {
+		return fmt.Errorf("dcel: %s property %q already exists", kind, name)
+	}
+	(*reg)[name] = p
+	return nil
+}
+
+// removeEdge deletes the values of the edge with the given id and of its
+// halfedges h1 and h2.
+func (p *properties) removeEdge(id int64, h1, h2 Halfedge) {
+	for _, prop := range p.edges {
+		prop.remove(id)
+	}
+	for _, prop := range p.halfedges {
+		prop.remove(h1)
+		prop.remove(h2)
+	}
+}
+
+// removeNode deletes the values of the node with the given id.
+func (p *properties) removeNode(id int64) {
+	for _, prop := range p.nodes {
+		prop.remove(id)
+	}
+}
+
+// removeFace deletes the values of the face with the given id.
+func (p *properties) removeFace(id int64) {
+	for _, prop := range p.faces {
+		prop.remove(id)
+	}
+}
+
+// saveFace returns a function that restores the property values of the face
+// with the given id. It is used when a face is removed and added back.
+func (p *properties) saveFace(id int64) (restore func()) {
+	saved := make(map[string]any)
+	for name, prop := range p.faces {
+		if x, ok := prop.value(id); ok {
+			saved[name] = x
+		}
+	}
+	return func() {
+		for name, x := range saved {
+			if prop, ok := p.faces[name]; ok {
+				prop.setValue(id, x)
+			}
+		}
+	}
+}
+
+// merge copies the values of the properties in other to the properties with
+// the same name and type in p. The IDs of nodes, edges and faces are mapped by
+// m and the halfedges by hedges, and values of unmapped keys are not copied.
+// The properties of other that p does not have are registered in p and the
+// properties of p with another type are left unchanged.
+func (p *properties) merge(other *properties, m IDMap, hedges map[Halfedge]Halfedge) {
+	byID := func(ids map[int64]int64) func(any) (any, bool) {
+		return func(key any) (any, bool) {
+			id, ok := ids[key.(int64)]
+			return id, ok
+		}
+	}
+	mergeValues(&p.nodes, other.nodes, byID(m.Nodes))
+	mergeValues(&p.edges, other.edges, byID(m.Edges))
+	mergeValues(&p.faces, other.faces, byID(m.Faces))
+	mergeValues(&p.halfedges, other.halfedges, func(key any) (any, bool) {
+		h, ok := hedges[key.(Halfedge)]
+		return h, ok
+	})
+}
+
+// mergeValues copies the values of the properties in src to the properties in
+// the registry reg as described by properties.merge.
+func mergeValues(reg *map[string]property, src map[string]property, key func(any) (any, bool)) {
+	for name, ps := range src {
+		pr, ok := (*reg)[name]
+		if !ok {
+			pr = ps.clone()
+			if *reg == nil {
+				*reg = make(map[string]property)
+			}
+			(*reg)[name] = pr
+		} else if reflect.TypeOf(pr) != reflect.TypeOf(ps) {
+			continue
+		}
+		ps.each(func(k, x any) {
+			if k, ok := key(k); ok {
+				pr.setValue(k, x)
+			}
+		})
+	}
+}
+
+// copyNode copies the values of the node with ID from to the node with ID to.
+func (p *properties) copyNode(from, to int64) {
+	for _, prop := range p.nodes {
+		if x, ok := prop.value(from); ok {
+			prop.setValue(to, x)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package dcel
+
+import (
+	"testing"
+
+	"gonum.org/v1/gonum/graph"
+)
+
+func TestNodeProperty(t *testing.T) {
+	g := newPolygons(rect(0, 0, 1, 1), rect(1, 0, 2, 1))
+	weight, err := AddNodeProperty[float64](g, "weight")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = AddNodeProperty[int](g, "weight"); err == nil {
+		t.Error("dcel: expected error for duplicate property name")
+	}
+	if p, ok := FindNodeProperty[float64](g, "weight"); !ok || p != weight {
+		t.Error("dcel: property not found")
+	}
+	if _, ok := FindNodeProperty[int](g, "weight"); ok {
+		t.Error("dcel: found property of wrong type")
+	}
+
+	for _, u := range graph.NodesOf(g.Nodes()) {
+		weight.Set(u.(Node), float64(u.ID()))
+	}
+	if weight.Len() != 6 || weight.Get(g.Node(3).(Node)) != 3 {
+		t.Errorf("dcel: wrong values: %v", weight.m)
+	}
+	g.RemoveNode(3)
+	u := g.AddNode(3)
+	if weight.Get(u) != 0 || weight.Len() != 5 {
+		t.Error("dcel: value of removed node not deleted")
+	}
+	if weight.Get(g.Node(4).(Node)) != 4 {
+		t.Error("dcel: value of remaining node changed")
+	}
+
+	g.RemoveNodeProperty("weight")
+	if _, ok := FindNodeProperty[float64](g, "weight"); ok {
+		t.Error("dcel: removed property found")
+	}
+	g.RemoveNode(4)
+	if weight.Len() != 5 {
+		t.Error("dcel: value deleted from removed property")
+	}
+}
+
+func TestEdgeProperty(t *testing.T) {
+	g := newPolygons(rect(0, 0, 1, 1), rect(1, 0, 2, 1))
+	label, err := AddEdgeProperty[string](g, "label")
+	if err != nil {
+		t.Fatal(err)
+	}
+	side, err := AddHalfedgeProperty[int](g, "side")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := g.EdgeBetween(1, 2).(Edge)
+	h1, h2 := e.Halfedges()
+	label.Set(e, "shared")
+	side.Set(h1, 1)
+	side.Set(h2, 2)
+	other := g.Halfedge(0, 1)
+	side.Set(other, 3)
+
+	g.RemoveEdge(1, 2)
+	if label.Len() != 0 || side.Len() != 1 || side.Get(other) != 3 {
+		t.Error("dcel: values of removed edge not deleted")
+	}
+	if len(g.Faces()) != 0 {
+		t.Errorf("dcel: wrong number of faces: %d", len(g.Faces()))
+	}
+}
+
+func TestFaceProperty(t *testing.T) {
+	g := newPolygons(rect(0, 0, 2, 1), rect(2, 0, 3, 1))
+	region, err := AddFaceProperty[int](g, "region")
+	if err != nil {
+		t.Fatal(err)
+	}
+	region.Set(g.Face(0), 10)
+	region.Set(g.Face(1), 11)
+
+	faces, err := g.TriangulateFace(g.Face(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if region.Get(faces[0]) != 10 || region.Get(faces[1]) != 0 || region.Get(g.Face(1)) != 11 {
+		t.Error("dcel: wrong values after triangulation")
+	}
+
+	g.RemoveFace(g.Face(1))
+	if region.Len() != 1 {
+		t.Error("dcel: value of removed face not deleted")
+	}
+	region.Delete(faces[0])
+	if region.Len() != 0 {
+		t.Error("dcel: value not deleted")
+	}
+}
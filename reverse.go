@@ -0,0 +1,163 @@
+package dcel
+
+import "fmt"
+
+// ReverseFace reverses the order of the halfedges around f so that f is
+// formed by the twins of its halfedges. The Face object, its ID, its property
+// values and all edges are kept. Observers are notified of the relinked
+// halfedges and of the reversed face.
+//
+// If f is not in the graph or if a twin of a halfedge of f has an adjacent
+// face, an error is returned and the graph is not modified.
+func (g *Graph) ReverseFace(f Face) error {
+	id := f.ID()
+	if g.faces[id] != f {
+		return fmt.Errorf("dcel: face %d not in graph", id)
+	}
+	hedges := g.HalfedgesAround(f)
+	for _, h := range hedges {
+		if tf := h.Twin().Face(); tf != nil {
+			return fmt.Errorf("dcel: cannot reverse face %d adjacent to face %d", id, tf.ID())
+		}
+	}
+
+	// At the start node of each halfedge out of f, the twin of the previous
+	// halfedge is followed by out in the rotation around the node. Swapping
+	// them moves f to the other side of its edges.
+	n := len(hedges)
+	var links [][2]Halfedge
+	for i, out := range hedges {
+		in := hedges[(i+n-1)%n]
+		inTwin, outTwin := in.Twin(), out.Twin()
+		next := outTwin.Next()
+		if next == inTwin {
+			// The node has no other edges, so the links stay.
+			continue
+		}
+		links = append(links, [2]Halfedge{inTwin.Prev(), out}, [2]Halfedge{outTwin, inTwin}, [2]Halfedge{in, next})
+	}
+	for _, l := range links {
+		l[0].SetNext(l[1])
+		l[1].SetPrev(l[0])
+	}
+	for i, h := range hedges {
+		h.SetFace(nil)
+		h.Twin().SetFace(f)
+		// Boundary nodes must refer to an outgoing halfedge without a face.
+		if u := h.From(); u.Halfedge() == hedges[(i+n-1)%n].Twin() {
+			u.SetHalfedge(h)
+		}
+	}
+	f.SetHalfedge(f.Halfedge().Twin())
+
+	for _, l := range links {
+		g.reconnected(l[0], l[1])
+	}
+	for _, o := range g.observers {
+		o.FaceReversed(f)
+	}
+	return nil
+}
+
+// ReverseComponent reverses the orientation of all faces in the connected
+// component of the graph that contains the node u. Every halfedge takes over
+// the adjacent face of its twin, so the halfedges of boundary loops become
+// halfedges of faces and vice versa, and the roles of Next and Prev are
+// swapped. The Face objects, edges and all IDs are kept. Observers are
+// notified of the relinked halfedges and of each reversed face.
+func (g *Graph) ReverseComponent(u Node) {
+	if g.nodes[u.ID()] != u {
+		return
+	}
+	var (
+		nodes   = []Node{u}
+		edges   []Edge
+		visited = map[int64]bool{u.ID(): true}
+		seen    = make(map[int64]bool)
+	)
+	for i := 0; i < len(nodes); i++ {
+		for _, h := range g.HalfedgesFrom(nodes[i].ID()) {
+			if e := h.Edge(); !seen[e.ID()] {
+				seen[e.ID()] = true
+				edges = append(edges, e)
+			}
+			if v := h.Twin().From(); !visited[v.ID()] {
+				visited[v.ID()] = true
+				nodes = append(nodes, v)
+			}
+		}
+	}
+	g.reverseHalfedges(nodes, edges)
+}
+
+// ReverseAll reverses the orientation of all faces in the graph as
+// ReverseComponent does for each connected component.
+func (g *Graph) ReverseAll() {
+	nodes := make([]Node, 0, len(g.nodes))
+	for _, id := range g.nodeIDs() {
+		nodes = append(nodes, g.nodes[id])
+	}
+	edges := make([]Edge, 0, len(g.edges))
+	for _, id := range g.edgeIDs() {
+		edges = append(edges, g.edges[id])
+	}
+	g.reverseHalfedges(nodes, edges)
+}
+
+// reverseHalfedges reverses the orientation of the faces adjacent to the
+// given edges. The edges must be all the edges attached to the given nodes.
+func (g *Graph) reverseHalfedges(nodes []Node, edges []Edge) {
+	type link struct {
+		h, next, old Halfedge
+		face         Face
+	}
+	links := make([]link, 0, 2*len(edges))
+	var faces []Face
+	for _, e := range edges {
+		h1, h2 := e.Halfedges()
+		for _, h := range [2]Halfedge{h1, h2} {
+			// The loop of the face of the twin is traversed backwards.
+			links = append(links, link{h: h, next: h.Twin().Prev().Twin(), old: h.Next(), face: h.Twin().Face()})
+			if f := h.Face(); f != nil && f.Halfedge() == h {
+				faces = append(faces, f)
+			}
+		}
+	}
+	for _, l := range links {
+		l.h.SetNext(l.next)
+		l.next.SetPrev(l.h)
+		l.h.SetFace(l.face)
+	}
+	for _, f := range faces {
+		f.SetHalfedge(f.Halfedge().Twin())
+	}
+
+	// Boundary nodes must refer to an outgoing halfedge without a face.
+	for _, u := range nodes {
+		start := u.Halfedge()
+		if start == nil {
+			continue
+		}
+		for h := start; ; {
+			if h.Face() == nil {
+				u.SetHalfedge(h)
+				break
+			}
+			h = h.Twin().Next()
+			if h == start {
+				break
+			}
+		}
+	}
+
+	for _, l := range links {
+		if l.next != l.old {
+			g.reconnected(l.h, l.next)
+		}
+	}
+	for _, f := range faces {
+		for _, o := range g.observers {
+			o.FaceReversed(f)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package dcel
+
+import (
+	"reflect"
+	"testing"
+
+	"gonum.org/v1/gonum/graph"
+)
+
+// checkLinks checks that the halfedges of g are consistently linked.
+func checkLinks(t *testing.T, g *Graph) {
+	t.Helper()
+	for _, e := range graph.EdgesOf(g.Edges()) {
+		h1, h2 := e.(Edge).Halfedges()
+		for _, h := range []Halfedge{h1, h2} {
+			if h.Next().Prev() != h || h.Prev().Next() != h || h.Twin().Twin() != h {
+				t.Fatalf("dcel: inconsistent links of halfedge from %d", h.From().ID())
+			}
+			if h.Next().From() != h.Twin().From() {
+				t.Fatalf("dcel: next halfedge does not start at the end of halfedge from %d", h.From().ID())
+			}
+			if h.Next().Face() != h.Face() {
+				t.Fatalf("dcel: halfedges of a loop have different faces")
+			}
+		}
+	}
+}
+
+func TestReverseComponent(t *testing.T) {
+	var corners []Point
+	for _, z := range []float64{-1, 1} {
+		for _, y := range []float64{-1, 1} {
+			for _, x := range []float64{-1, 1} {
+				corners = append(corners, Point{X: x, Y: y, Z: z})
+			}
+		}
+	}
+	cube := newMesh(corners, [][]int{
+		{0, 2, 3, 1}, {4, 5, 7, 6}, {0, 1, 5, 4}, {2, 6, 7, 3}, {0, 4, 6, 2}, {1, 3, 7, 5},
+	})
+	// Add a separate square.
+	for i, p := range rect(5, 5, 6, 6) {
+		addPointNode(cube, int64(10+i), p)
+	}
+	if err := cube.AddFace(6, NodeID(10), NodeID(11), NodeID(12), NodeID(13)); err != nil {
+		t.Fatal(err)
+	}
+	r := &recorder{g: cube}
+	cube.AddObserver(r)
+
+	cube.ReverseComponent(cube.Node(0).(Node))
+	checkLinks(t, cube)
+	if len(r.events) != 6 {
+		t.Errorf("dcel: wrong number of reversed faces: %d", len(r.events))
+	}
+	for _, f := range cube.Faces() {
+		hedges := cube.HalfedgesAround(f)
+		if len(hedges) != 4 {
+			t.Fatalf("dcel: face %d has %d halfedges", f.ID(), len(hedges))
+		}
+		a, b, c := point(hedges[0].From()), point(hedges[1].From()), point(hedges[2].From())
+		n := b.Sub(a).Cross(c.Sub(a))
+		switch {
+		case f.ID() == 6 && n.Z <= 0:
+			t.Error("dcel: face of another component reversed")
+		case f.ID() != 6 && n.Dot(a) >= 0:
+			t.Errorf("dcel: face %d is not oriented inwards", f.ID())
+		}
+	}
+
+	// The boundary of the square becomes its face and vice versa.
+	cube.ReverseAll()
+	checkLinks(t, cube)
+	if faceArea(cube, cube.Face(6)) != -1 {
+		t.Error("dcel: square not reversed")
+	}
+	if h := cube.Halfedge(10, 11); h.Face() != nil || h.Twin().Face() != cube.Face(6) {
+		t.Error("dcel: wrong faces of reversed square")
+	}
+	for id := int64(10); id < 14; id++ {
+		if cube.Node(id).(Node).Halfedge().Face() != nil {
+			t.Errorf("dcel: boundary node %d refers to a halfedge with a face", id)
+		}
+	}
+	if len(cube.BoundaryLoops()) != 1 {
+		t.Errorf("dcel: wrong number of boundary loops: %d", len(cube.BoundaryLoops()))
+	}
+}
+
+func TestReverseFace(t *testing.T) {
+	g := newPolygons(rect(0, 0, 1, 1), rect(1, 0, 2, 1), rect(3, 0, 4, 1))
+	label, err := AddFaceProperty[string](g, "label")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := g.Face(2)
+	label.Set(f, "separate")
+	r := &recorder{g: g}
+	g.AddObserver(r)
+
+	if err := g.ReverseFace(f); err != nil {
+		t.Fatal(err)
+	}
+	checkLinks(t, g)
+	if !reflect.DeepEqual(r.events, []string{"~f2"}) {
+		t.Errorf("dcel: wrong events: %v", r.events)
+	}
+	if g.Face(2) != f || label.Get(f) != "separate" {
+		t.Error("dcel: reversed face not kept")
+	}
+	if faceArea(g, f) != -1 {
+		t.Error("dcel: face not reversed")
+	}
+
+	// Squares that share an edge can be reversed only together.
+	r.events = nil
+	if err := g.ReverseFace(g.Face(0)); err == nil {
+		t.Error("dcel: expected error for a face with a neighbor")
+	}
+	if faceArea(g, g.Face(0)) != 1 || len(r.events) != 0 || r.reconnections != 0 {
+		t.Error("dcel: graph modified by a failed reversal")
+	}
+	g.ReverseComponent(g.Node(0).(Node))
+	checkLinks(t, g)
+	if faceArea(g, g.Face(0)) != -1 || faceArea(g, g.Face(1)) != -1 || faceArea(g, f) != -1 {
+		t.Error("dcel: wrong faces reversed")
+	}
+
+	// The reversed faces can be extended by faces with the same orientation.
+	if err := g.AddFace(g.NewFaceID(), g.Node(0), g.Node(3), g.Node(2), g.Node(1)); err == nil {
+		t.Error("dcel: expected error for a face over used halfedges")
+	}
+	u := addPointNode(g, g.NewNodeID(), Point{X: 0.5, Y: -1})
+	if err := g.AddFace(g.NewFaceID(), g.Node(0), g.Node(1), u); err != nil {
+		t.Errorf("dcel: adding a face to reversed faces failed: %v", err)
+	}
+	checkLinks(t, g)
+}
@@ -0,0 +1,302 @@
+package dcel
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ReadSTL reads a triangle mesh in the ASCII or binary STL format from r and
+// returns it as a graph whose nodes and faces are allocated by items. If items
+// is nil, PointBase will be used. The nodes allocated by items must implement
+// PointNode.
+//
+// Vertices at exactly the same position share a node, so the facets of the
+// mesh form a connected graph. Facets with coincident vertices are skipped and
+// add no nodes. Nodes get IDs in the order of the first occurrence of their
+// position in the remaining facets and faces get IDs returned by NewFaceID in
+// the order of facets. Facet normals are ignored and an ASCII file may hold
+// several solids, whose facets are all read. If the facets cannot be
+// added to the graph, for example because the mesh is not manifold or is not
+// oriented consistently, the error from AddFace is returned.
+func ReadSTL(r io.Reader, items Items) (*Graph, error) {
+	if items == nil {
+		items = PointBase{}
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var tris [][3]Point
+	if isBinarySTL(data) {
+		tris = readBinarySTL(data)
+	} else {
+		tris, err = readASCIISTL(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	g := New(items)
+	nodes := make(map[Point]Node)
+	for _, tri := range tris {
+		if tri[0] == tri[1] || tri[1] == tri[2] || tri[2] == tri[0] {
+			continue
+		}
+		var face [3]Node
+		for i, p := range tri {
+			u, ok := nodes[p]
+			if !ok {
+				u = g.AddNode(g.NewNodeID())
+				pu, ok := u.(PointNode)
+				if !ok {
+					return nil, errors.New("dcel: nodes do not implement PointNode")
+				}
+				pu.SetPoint(p)
+				nodes[p] = u
+			}
+			face[i] = u
+		}
+		if err := g.AddFace(g.NewFaceID(), face[0], face[1], face[2]); err != nil {
+			return nil, err
+		}
+	}
+	return g, nil
+}
+
+// isBinarySTL returns whether data has the size of a binary STL file with the
+// number of facets given in its header. ASCII files cannot be recognized by
+// the leading "solid" because some binary files start with it too.
+func isBinarySTL(data []byte) bool {
+	if len(data) < 84 {
+		return false
+	}
+	n := binary.LittleEndian.Uint32(data[80:84])
+	return uint64(len(data)) == 84+50*uint64(n)
+}
+
+// readBinarySTL returns the triangles of a binary STL file.
+func readBinarySTL(data []byte) [][3]Point {
+	n := binary.LittleEndian.Uint32(data[80:84])
+	tris := make([][3]Point, n)
+	for i := range tris {
+		// Skip the normal and read the vertices.
+		facet := data[84+50*i+12:]
+		for k := range tris[i] {
+			var c [3]float64
+			for j := range c {
+				bits := binary.LittleEndian.Uint32(facet[12*k+4*j:])
+				c[j] = float64(math.Float32frombits(bits))
+			}
+			tris[i][k] = Point{X: c[0], Y: c[1], Z: c[2]}
+		}
+	}
+	return tris
+}
+
+// readASCIISTL returns the triangles of an ASCII STL file. The file is parsed
+// line by line with the grammar
+//
+//	solid name
+//	  facet normal ni nj nk
+//	    outer loop
+//	      vertex x y z
+//	      vertex x y z
+//	      vertex x y z
+//	    endloop
+//	  endfacet
+//	  ...
+//	endsolid name
+//
+// where the names are arbitrary text and only the first word of a line is
+// matched against the keywords. Several solids may follow each other.
+func readASCIISTL(data []byte) ([][3]Point, error) {
+	const (
+		outside   = iota // Before a solid or after endsolid.
+		inSolid          // After solid or endfacet.
+		inFacet          // After facet normal.
+		inLoop           // After outer loop.
+		afterLoop        // After endloop.
+	)
+	var (
+		tris   [][3]Point
+		facet  []Point
+		state  = outside
+		solids int
+		line   int
+	)
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	// Allow names of any length.
+	sc.Buffer(nil, len(data)+1)
+	for sc.Scan() {
+		line++
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		switch kw := fields[0]; {
+		case state == outside && kw == "solid":
+			state = inSolid
+			solids++
+		case state == inSolid && kw == "endsolid":
+			state = outside
+		case state == inSolid && kw == "facet" && len(fields) == 5 && fields[1] == "normal":
+			// The normal is ignored but it must be valid.
+			if _, err := parseSTLPoint(fields[2:], line); err != nil {
+				return nil, err
+			}
+			facet = facet[:0]
+			state = inFacet
+		case state == inFacet && kw == "outer" && len(fields) == 2 && fields[1] == "loop":
+			state = inLoop
+		case state == inLoop && kw == "vertex" && len(fields) == 4:
+			p, err := parseSTLPoint(fields[1:], line)
+			if err != nil {
+				return nil, err
+			}
+			facet = append(facet, p)
+		case state == inLoop && kw == "endloop" && len(fields) == 1:
+			if len(facet) != 3 {
+				return nil, fmt.Errorf("dcel: STL facet %d has %d vertices", len(tris), len(facet))
+			}
+			state = afterLoop
+		case state == afterLoop && kw == "endfacet" && len(fields) == 1:
+			tris = append(tris, [3]Point{facet[0], facet[1], facet[2]})
+			state = inSolid
+		default:
+			return nil, fmt.Errorf("dcel: unexpected %q in STL data at line %d", sc.Text(), line)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if solids == 0 {
+		return nil, errors.New("dcel: invalid STL data")
+	}
+	if state != outside {
+		return nil, errors.New("dcel: unexpected end of STL data, missing endsolid")
+	}
+	return tris, nil
+}
+
+// parseSTLPoint returns the point with the coordinates given by the three
+// fields of the STL line with the given number.
+func parseSTLPoint(fields []string, line int) (Point, error) {
+	var c [3]float64
+	for j := range c {
+		x, err := strconv.ParseFloat(fields[j], 64)
+		if err != nil {
+			return Point{}, fmt.Errorf("dcel: invalid STL coordinate %q at line %d", fields[j], line)
+		}
+		c[j] = x
+	}
+	return Point{X: c[0], Y: c[1], Z: c[2]}, nil
+}
+
+// WriteSTL writes the faces of g to w in the ASCII STL format as a solid with
+// the given name. The normals of facets are computed from the positions of
+// their nodes, and faces with more than three nodes are split into triangles
+// by ear clipping as in TriangulateFace, so that faces that are simple
+// polygons are written as triangles that do not overlap. Faces are written in
+// the order of their IDs. If a node of a face does not carry a position, an
+// error is returned.
+func WriteSTL(w io.Writer, g *Graph, name string) error {
+	tris, err := stlTriangles(g)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "solid %s\n", name)
+	for _, tri := range tris {
+		n := facetNormal(tri)
+		fmt.Fprintf(bw, "  facet normal %g %g %g\n    outer loop\n", n.X, n.Y, n.Z)
+		for _, p := range tri {
+			fmt.Fprintf(bw, "      vertex %g %g %g\n", p.X, p.Y, p.Z)
+		}
+		fmt.Fprint(bw, "    endloop\n  endfacet\n")
+	}
+	fmt.Fprintf(bw, "endsolid %s\n", name)
+	return bw.Flush()
+}
+
+// WriteBinarySTL writes the faces of g to w in the binary STL format. The
+// coordinates are written as float32 values. See WriteSTL for how the faces
+// are written.
+func WriteBinarySTL(w io.Writer, g *Graph) error {
+	tris, err := stlTriangles(g)
+	if err != nil {
+		return err
+	}
+	if uint64(len(tris)) > math.MaxUint32 {
+		return errors.New("dcel: too many triangles for binary STL")
+	}
+	bw := bufio.NewWriter(w)
+	var header [84]byte
+	binary.LittleEndian.PutUint32(header[80:], uint32(len(tris)))
+	bw.Write(header[:])
+	var facet [50]byte
+	for _, tri := range tris {
+		n := facetNormal(tri)
+		for k, p := range [4]Point{n, tri[0], tri[1], tri[2]} {
+			for j, x := range [3]float64{p.X, p.Y, p.Z} {
+				binary.LittleEndian.PutUint32(facet[12*k+4*j:], math.Float32bits(float32(x)))
+			}
+		}
+		bw.Write(facet[:])
+	}
+	return bw.Flush()
+}
+
+// stlTriangles returns the triangles of the faces of g in the order of face
+// IDs.
+func stlTriangles(g *Graph) ([][3]Point, error) {
+	var (
+		tris [][3]Point
+		// The faces are triangulated in an empty graph so that the
+		// diagonals are not restricted by the edges of g, which is not
+		// modified.
+		empty = New(nil)
+	)
+	for _, id := range g.faceIDs() {
+		var nodes []Node
+		for _, h := range g.HalfedgesAround(g.faces[id]) {
+			nodes = append(nodes, h.From())
+		}
+		pts, ok := positions(nodes)
+		if !ok {
+			return nil, fmt.Errorf("dcel: node of face %d does not have a position", id)
+		}
+		if len(pts) == 3 {
+			tris = append(tris, [3]Point{pts[0], pts[1], pts[2]})
+			continue
+		}
+		clipped, ok := empty.earClip(nodes, pts)
+		if !ok {
+			// Without edges in the way, ear clipping fails only if
+			// rounding makes the projection of a nearly degenerate
+			// face clockwise. A fan cannot fail in an empty graph.
+			clipped, _ = empty.fan(nodes)
+		}
+		for _, t := range clipped {
+			tris = append(tris, [3]Point{pts[t[0]], pts[t[1]], pts[t[2]]})
+		}
+	}
+	return tris, nil
+}
+
+// facetNormal returns the unit normal of the triangle or the zero vector if
+// the triangle is degenerate.
+func facetNormal(tri [3]Point) Point {
+	n := tri[1].Sub(tri[0]).Cross(tri[2].Sub(tri[0]))
+	l := n.Norm()
+	if l == 0 {
+		return Point{}
+	}
+	return n.Scale(1 / l)
+}
@@ -0,0 +1,164 @@
+package dcel
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// newTriangulatedCube returns a graph with the surface of the cube [-1,1]³
+// formed by 12 triangles oriented outwards.
+func newTriangulatedCube() *Graph {
+	var corners []Point
+	for _, z := range []float64{-1, 1} {
+		for _, y := range []float64{-1, 1} {
+			for _, x := range []float64{-1, 1} {
+				corners = append(corners, Point{X: x, Y: y, Z: z})
+			}
+		}
+	}
+	return newMesh(corners, [][]int{
+		{0, 2, 3}, {0, 3, 1}, {4, 5, 7}, {4, 7, 6}, {0, 1, 5}, {0, 5, 4},
+		{2, 6, 7}, {2, 7, 3}, {0, 4, 6}, {0, 6, 2}, {1, 3, 7}, {1, 7, 5},
+	})
+}
+
+func TestSTL(t *testing.T) {
+	cube := newTriangulatedCube()
+	for _, binary := range []bool{false, true} {
+		var buf bytes.Buffer
+		var err error
+		if binary {
+			err = WriteBinarySTL(&buf, cube)
+		} else {
+			err = WriteSTL(&buf, cube, "cube")
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !binary && !strings.Contains(buf.String(), "facet normal 0 0 -1\n") {
+			t.Error("dcel: missing normal of the bottom face")
+		}
+		if binary && buf.Len() != 84+50*12 {
+			t.Errorf("dcel: wrong size of binary STL: %d", buf.Len())
+		}
+
+		g, err := ReadSTL(&buf, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkClosed(t, g, 12, 3)
+		if g.Nodes().Len() != 8 {
+			t.Errorf("dcel: vertices not deduplicated: %d nodes", g.Nodes().Len())
+		}
+		for _, id := range g.faceIDs() {
+			got, want := g.HalfedgesAround(g.faces[id]), cube.HalfedgesAround(cube.faces[id])
+			for i := range got {
+				if point(got[i].From()) != point(want[i].From()) {
+					t.Errorf("dcel: wrong node %d of face %d", i, id)
+				}
+			}
+		}
+	}
+}
+
+func TestWriteSTLConcave(t *testing.T) {
+	// A concave quadrilateral whose fan around the first node would have
+	// an inverted triangle.
+	g := newMesh([]Point{{X: 4}, {X: 2, Y: 3}, {}, {X: 2, Y: 1}}, [][]int{{0, 1, 2, 3}})
+	var buf bytes.Buffer
+	if err := WriteSTL(&buf, g, "chevron"); err != nil {
+		t.Fatal(err)
+	}
+	r, err := ReadSTL(&buf, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Faces()) != 2 {
+		t.Fatalf("dcel: wrong number of facets: %d", len(r.Faces()))
+	}
+	var total float64
+	for _, f := range r.Faces() {
+		a := faceArea(r, f)
+		if a <= 0 {
+			t.Errorf("dcel: facet %d is inverted", f.ID())
+		}
+		total += a
+	}
+	if total != 4 {
+		t.Errorf("dcel: wrong area of facets: got %v, want 4", total)
+	}
+}
+
+func TestReadSTL(t *testing.T) {
+	const data = `solid square
+  facet normal 0 0 1
+    outer loop
+      vertex 0 0 0
+      vertex 1 0 0
+      vertex 1 1 0
+    endloop
+  endfacet
+  facet normal 0 0 0
+    outer loop
+      vertex 0 0 0
+      vertex 1 1 0
+      vertex 1 1 0
+    endloop
+  endfacet
+  facet normal 0 0 1
+    outer loop
+      vertex 0 0 0
+      vertex 1 1 0
+      vertex 0 1 0
+    endloop
+  endfacet
+  facet normal 0 0 0
+    outer loop
+      vertex 0 0 0
+      vertex 0 0 0
+      vertex 5 5 0
+    endloop
+  endfacet
+endsolid square
+`
+	g, err := ReadSTL(strings.NewReader(data), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Nodes().Len() != 4 || len(g.Faces()) != 2 || len(g.BoundaryLoops()) != 1 {
+		t.Error("dcel: wrong square read from STL")
+	}
+
+	// Names that contain keywords and a second solid.
+	named := strings.Replace(data, "solid square", "solid facet vertex endsolid", 1) +
+		"solid\nfacet normal 0 0 1\nouter loop\nvertex 1 0 0\nvertex 2 0 0\nvertex 1 1 0\nendloop\nendfacet\nendsolid\n"
+	g, err = ReadSTL(strings.NewReader(named), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Nodes().Len() != 5 || len(g.Faces()) != 3 {
+		t.Error("dcel: wrong solids with keywords in names read from STL")
+	}
+
+	const facet = "facet normal 0 0 1\nouter loop\nvertex 0 0 0\nvertex 1 0 0\nvertex 1 1 0\nendloop\nendfacet\n"
+	for _, data := range []string{
+		"",
+		"solid x facet outer loop vertex 0 0 0 vertex 1 0 0 vertex 1 1 0 endloop endfacet endsolid x",
+		"solid x\nfacet normal 0 0 1\nouter loop\nvertex 0 0 0\nvertex 1 0 0\nendloop\nendfacet\nendsolid x\n",
+		"solid x\nfacet normal 0 0 1\nouter loop\nvertex 0 0 a\n",
+		"solid x\nvertex 0 0 0\n" + facet + "endsolid x\n",
+		"solid x\n" + facet + "vertex 0 0 0\nendsolid x\n",
+		"solid x\n" + facet,
+		"solid x\n" + facet[:len(facet)-len("endfacet\n")] + "endsolid x\n",
+		"solid x\nfacet normal 0 0\nouter loop\nvertex 0 0 0\nvertex 1 0 0\nvertex 1 1 0\nendloop\nendfacet\nendsolid x\n",
+		facet,
+	} {
+		if _, err := ReadSTL(strings.NewReader(data), nil); err == nil {
+			t.Errorf("dcel: expected error for %q", data)
+		}
+	}
+	if _, err := ReadSTL(strings.NewReader(data), Base{}); err == nil {
+		t.Error("dcel: expected error for nodes without a position")
+	}
+}
@@ -0,0 +1,358 @@
+package dcel
+
+import (
+	"fmt"
+	"math"
+)
+
+// LoopSubdivide returns the graph obtained from the triangle mesh formed by the
+// faces of g by the given number of levels of Loop subdivision. Each level
+// inserts a node on every edge and splits every triangle into four.
+//
+// The refined graph uses the Items and the WeightFunc of g. The nodes of g keep
+// their IDs, the inserted nodes and all faces get new IDs. Nodes of g that are
+// not adjacent to any face are kept as isolated nodes and edges of g that are
+// not adjacent to any face are dropped.
+//
+// If all nodes of g carry positions, the positions of the refined nodes are
+// computed by the Loop rules. Boundary edges and nodes on a single boundary
+// are refined as a cubic B-spline curve, other boundary nodes keep their
+// positions.
+//
+// If a face of g is not a triangle, an error is returned. LoopSubdivide panics
+// if levels is less than 1.
+func (g *Graph) LoopSubdivide(levels int) (*Graph, error) {
+	return subdivide(g, levels, loopLevel)
+}
+
+// CatmullClark returns the graph obtained from the polygon mesh formed by the
+// faces of g by the given number of levels of Catmull–Clark subdivision. Each
+// level inserts a node on every edge and in every face and splits every face
+// with n nodes into n quadrilaterals.
+//
+// The refined graph is built as in LoopSubdivide. If all nodes of g carry
+// positions, the positions of the refined nodes are computed by the
+// Catmull–Clark rules with boundaries treated as in LoopSubdivide.
+//
+// CatmullClark panics if levels is less than 1.
+func (g *Graph) CatmullClark(levels int) (*Graph, error) {
+	return subdivide(g, levels, catmullClarkLevel)
+}
+
+// Sqrt3 returns the graph obtained from the triangle mesh formed by the faces
+// of g by the given number of levels of √3 subdivision. Each level inserts a
+// node in every triangle, connects it to the nodes of the triangle and flips
+// the edges of g that have two adjacent faces.
+//
+// The refined graph is built as in LoopSubdivide. If all nodes of g carry
+// positions, the positions of the refined nodes are computed by the √3 rules.
+// Boundary edges are not refined and boundary nodes keep their positions.
+//
+// If a face of g is not a triangle, an error is returned. Sqrt3 panics if
+// levels is less than 1.
+func (g *Graph) Sqrt3(levels int) (*Graph, error) {
+	return subdivide(g, levels, sqrt3Level)
+}
+
+// subdivide applies level to g the given number of times.
+func subdivide(g *Graph, levels int, level func(*Graph) (*Graph, error)) (*Graph, error) {
+	if levels < 1 {
+		panic(fmt.Sprintf("dcel: invalid number of subdivision levels: %d", levels))
+	}
+	for i := 0; i < levels; i++ {
+		var err error
+		g, err = level(g)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return g, nil
+}
+
+// loopLevel returns one level of Loop subdivision of g.
+func loopLevel(g *Graph) (*Graph, error) {
+	if err := triangles(g); err != nil {
+		return nil, err
+	}
+	sd := newSubdivision(g)
+	for _, id := range g.edgeIDs() {
+		h1, h2 := g.edges[id].Halfedges()
+		if h1.Face() == nil && h2.Face() == nil {
+			continue
+		}
+		u := sd.addEdgeNode(id)
+		if !sd.geometric {
+			continue
+		}
+		a, b := point(h1.From()), point(h2.From())
+		if h1.Face() == nil || h2.Face() == nil {
+			setPoint(u, a.Add(b).Scale(0.5))
+			continue
+		}
+		c, d := point(h1.Prev().From()), point(h2.Prev().From())
+		setPoint(u, a.Add(b).Scale(3.0/8).Add(c.Add(d).Scale(1.0/8)))
+	}
+	if sd.geometric {
+		for _, id := range g.nodeIDs() {
+			u := g.nodes[id]
+			p := point(u)
+			adjacent, boundary := star(g, u)
+			switch {
+			case len(boundary) > 0:
+				p = boundaryPoint(p, boundary)
+			case len(adjacent) > 0:
+				n := float64(len(adjacent))
+				c := 3.0/8 + math.Cos(2*math.Pi/n)/4
+				w := 5.0/8 - c*c
+				pts, _ := positions(adjacent)
+				p = p.Scale(1 - w).Add(centroid(pts).Scale(w))
+			}
+			setPoint(sd.s.nodes[id], p)
+		}
+	}
+
+	for _, id := range g.faceIDs() {
+		h := g.faces[id].Halfedge()
+		a, b, c := sd.node(h.From()), sd.node(h.Next().From()), sd.node(h.Prev().From())
+		ab, bc, ca := sd.edgeNode(h), sd.edgeNode(h.Next()), sd.edgeNode(h.Prev())
+		for _, t := range [][]Node{{a, ab, ca}, {ab, b, bc}, {ca, bc, c}, {ab, bc, ca}} {
+			if err := sd.addFace(t...); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return sd.s, nil
+}
+
+// catmullClarkLevel returns one level of Catmull–Clark subdivision of g.
+func catmullClarkLevel(g *Graph) (*Graph, error) {
+	sd := newSubdivision(g)
+	var (
+		faceNodes  = make(map[int64]Node)
+		facePoints = make(map[int64]Point)
+	)
+	for _, id := range g.faceIDs() {
+		u := sd.addNode()
+		faceNodes[id] = u
+		if !sd.geometric {
+			continue
+		}
+		var pts []Point
+		for _, h := range g.HalfedgesAround(g.faces[id]) {
+			pts = append(pts, point(h.From()))
+		}
+		facePoints[id] = centroid(pts)
+		setPoint(u, facePoints[id])
+	}
+	for _, id := range g.edgeIDs() {
+		h1, h2 := g.edges[id].Halfedges()
+		if h1.Face() == nil && h2.Face() == nil {
+			continue
+		}
+		u := sd.addEdgeNode(id)
+		if !sd.geometric {
+			continue
+		}
+		a, b := point(h1.From()), point(h2.From())
+		if h1.Face() == nil || h2.Face() == nil {
+			setPoint(u, a.Add(b).Scale(0.5))
+			continue
+		}
+		c, d := facePoints[h1.Face().ID()], facePoints[h2.Face().ID()]
+		setPoint(u, a.Add(b).Add(c).Add(d).Scale(0.25))
+	}
+	if sd.geometric {
+		for _, id := range g.nodeIDs() {
+			u := g.nodes[id]
+			p := point(u)
+			adjacent, boundary := star(g, u)
+			switch {
+			case len(boundary) > 0:
+				p = boundaryPoint(p, boundary)
+			case len(adjacent) > 0:
+				// The average of the face points and the average of the
+				// midpoints of the edges around u.
+				var faces []Point
+				for _, h := range g.HalfedgesFrom(id) {
+					if h.Face() != nil {
+						faces = append(faces, facePoints[h.Face().ID()])
+					}
+				}
+				pts, _ := positions(adjacent)
+				f := centroid(faces)
+				r := p.Add(centroid(pts)).Scale(0.5)
+				n := float64(len(adjacent))
+				p = f.Add(r.Scale(2)).Add(p.Scale(n - 3)).Scale(1 / n)
+			}
+			setPoint(sd.s.nodes[id], p)
+		}
+	}
+
+	for _, id := range g.faceIDs() {
+		hedges := g.HalfedgesAround(g.faces[id])
+		for i, h := range hedges {
+			prev := hedges[(i+len(hedges)-1)%len(hedges)]
+			err := sd.addFace(sd.node(h.From()), sd.edgeNode(h), faceNodes[id], sd.edgeNode(prev))
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return sd.s, nil
+}
+
+// sqrt3Level returns one level of √3 subdivision of g.
+func sqrt3Level(g *Graph) (*Graph, error) {
+	if err := triangles(g); err != nil {
+		return nil, err
+	}
+	sd := newSubdivision(g)
+	faceNodes := make(map[int64]Node)
+	for _, id := range g.faceIDs() {
+		u := sd.addNode()
+		faceNodes[id] = u
+		if !sd.geometric {
+			continue
+		}
+		h := g.faces[id].Halfedge()
+		setPoint(u, centroid([]Point{point(h.From()), point(h.Next().From()), point(h.Prev().From())}))
+	}
+	if sd.geometric {
+		for _, id := range g.nodeIDs() {
+			u := g.nodes[id]
+			p := point(u)
+			adjacent, boundary := star(g, u)
+			if len(boundary) == 0 && len(adjacent) > 0 {
+				n := float64(len(adjacent))
+				w := (4 - 2*math.Cos(2*math.Pi/n)) / 9
+				pts, _ := positions(adjacent)
+				p = p.Scale(1 - w).Add(centroid(pts).Scale(w))
+			}
+			setPoint(sd.s.nodes[id], p)
+		}
+	}
+
+	for _, id := range g.edgeIDs() {
+		h1, h2 := g.edges[id].Halfedges()
+		a, b := sd.node(h1.From()), sd.node(h2.From())
+		var faces [][]Node
+		switch f1, f2 := h1.Face(), h2.Face(); {
+		case f1 != nil && f2 != nil:
+			// Flip the edge.
+			c1, c2 := faceNodes[f1.ID()], faceNodes[f2.ID()]
+			faces = [][]Node{{a, c2, c1}, {b, c1, c2}}
+		case f1 != nil:
+			faces = [][]Node{{a, b, faceNodes[f1.ID()]}}
+		case f2 != nil:
+			faces = [][]Node{{b, a, faceNodes[f2.ID()]}}
+		}
+		for _, f := range faces {
+			if err := sd.addFace(f...); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return sd.s, nil
+}
+
+// subdivision is one level of subdivision of a graph.
+type subdivision struct {
+	// g is the graph being subdivided and s is the refined graph.
+	g, s *Graph
+	// geometric is whether all nodes of g carry positions.
+	geometric bool
+	// edges maps the IDs of edges of g to the nodes inserted on them.
+	edges map[int64]Node
+}
+
+// newSubdivision returns a subdivision of g whose refined graph contains the
+// nodes of g.
+func newSubdivision(g *Graph) *subdivision {
+	sd := &subdivision{
+		g:         g,
+		s:         NewWeighted(g.items, g.weight),
+		geometric: true,
+		edges:     make(map[int64]Node),
+	}
+	for _, id := range g.nodeIDs() {
+		sd.s.AddNode(id)
+		if _, ok := position(g.nodes[id]); !ok {
+			sd.geometric = false
+		}
+	}
+	return sd
+}
+
+// node returns the node of the refined graph that corresponds to the node u
+// of g.
+func (sd *subdivision) node(u Node) Node {
+	return sd.s.nodes[u.ID()]
+}
+
+// addNode adds a new node to the refined graph.
+func (sd *subdivision) addNode() Node {
+	return sd.s.AddNode(sd.s.NewNodeID())
+}
+
+// addEdgeNode adds a new node to the refined graph for the edge of g with the
+// given id.
+func (sd *subdivision) addEdgeNode(id int64) Node {
+	u := sd.addNode()
+	sd.edges[id] = u
+	return u
+}
+
+// edgeNode returns the node inserted on the edge of h.
+func (sd *subdivision) edgeNode(h Halfedge) Node {
+	return sd.edges[h.Edge().ID()]
+}
+
+// addFace adds a new face with the given nodes to the refined graph.
+func (sd *subdivision) addFace(nodes ...Node) error {
+	return sd.s.AddFace(sd.s.NewFaceID(), toGraphNodes(nodes)...)
+}
+
+// triangles returns an error if a face of g is not a triangle.
+func triangles(g *Graph) error {
+	for _, id := range g.faceIDs() {
+		if len(g.HalfedgesAround(g.faces[id])) != 3 {
+			return fmt.Errorf("dcel: face %d is not a triangle", id)
+		}
+	}
+	return nil
+}
+
+// star returns the nodes adjacent to u across edges that have an adjacent
+// face and those of them that are adjacent across boundary edges.
+func star(g *Graph, u Node) (adjacent, boundary []Node) {
+	for _, h := range g.HalfedgesFrom(u.ID()) {
+		f1, f2 := h.Face(), h.Twin().Face()
+		if f1 == nil && f2 == nil {
+			continue
+		}
+		v := h.Twin().From()
+		adjacent = append(adjacent, v)
+		if f1 == nil || f2 == nil {
+			boundary = append(boundary, v)
+		}
+	}
+	return adjacent, boundary
+}
+
+// boundaryPoint returns the refined position p of a boundary node with the
+// given neighbors along the boundary. Nodes on more than one boundary keep
+// their position.
+func boundaryPoint(p Point, boundary []Node) Point {
+	if len(boundary) != 2 {
+		return p
+	}
+	a, b := point(boundary[0]), point(boundary[1])
+	return p.Scale(3.0 / 4).Add(a.Add(b).Scale(1.0 / 8))
+}
+
+// setPoint sets the position of u if it is a PointNode.
+func setPoint(u Node, p Point) {
+	if pu, ok := u.(PointNode); ok {
+		pu.SetPoint(p)
+	}
+}
@@ -0,0 +1,200 @@
+package dcel
+
+import (
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/graph"
+)
+
+// newMesh returns a graph with nodes at pts and faces given by indices into
+// pts.
+func newMesh(pts []Point, faces [][]int) *Graph {
+	g := New(PointBase{})
+	for i, p := range pts {
+		addPointNode(g, int64(i), p)
+	}
+	for _, f := range faces {
+		nodes := make([]graph.Node, len(f))
+		for i, k := range f {
+			nodes[i] = g.Node(int64(k))
+		}
+		if err := g.AddFace(g.NewFaceID(), nodes...); err != nil {
+			panic(err)
+		}
+	}
+	return g
+}
+
+// checkClosed checks that g has the given number of faces with n nodes each,
+// that it has no boundary and that its faces are oriented outwards from the
+// origin.
+func checkClosed(t *testing.T, g *Graph, faces, n int) {
+	t.Helper()
+	if len(g.Faces()) != faces {
+		t.Errorf("dcel: wrong number of faces: got %d, want %d", len(g.Faces()), faces)
+	}
+	if len(g.BoundaryLoops()) != 0 {
+		t.Error("dcel: unexpected boundary")
+	}
+	for _, f := range g.Faces() {
+		hedges := g.HalfedgesAround(f)
+		if len(hedges) != n {
+			t.Fatalf("dcel: face %d has %d nodes", f.ID(), len(hedges))
+		}
+		a, b, c := point(hedges[0].From()), point(hedges[1].From()), point(hedges[2].From())
+		if b.Sub(a).Cross(c.Sub(a)).Dot(a) <= 0 {
+			t.Errorf("dcel: face %d is not oriented outwards", f.ID())
+		}
+	}
+}
+
+func closeTo(p, q Point) bool { return dist(p, q) < 1e-12 }
+
+func TestLoopSubdivide(t *testing.T) {
+	octahedron := newMesh(
+		[]Point{{X: 1}, {Y: 1}, {X: -1}, {Y: -1}, {Z: 1}, {Z: -1}},
+		[][]int{{0, 1, 4}, {1, 2, 4}, {2, 3, 4}, {3, 0, 4}, {1, 0, 5}, {2, 1, 5}, {3, 2, 5}, {0, 3, 5}},
+	)
+	s, err := octahedron.LoopSubdivide(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkClosed(t, s, 128, 3)
+	if s.Nodes().Len() != 66 {
+		t.Errorf("dcel: wrong number of nodes: %d", s.Nodes().Len())
+	}
+	// The weight of the centroid of the neighbors of a node of valence 4 is
+	// 31/64. The neighbors of node 4 after the first level are at Z = 3/8.
+	if p := point(s.Node(4).(Node)); !closeTo(p, Point{Z: 33.0/64*33/64 + 3.0/8*31/64}) {
+		t.Errorf("dcel: wrong position of node 4: %v", p)
+	}
+
+	triangle := newMesh([]Point{{X: 0}, {X: 8}, {Y: 8}}, [][]int{{0, 1, 2}})
+	s, err = triangle.LoopSubdivide(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Faces()) != 4 || s.Nodes().Len() != 6 {
+		t.Errorf("dcel: wrong size of refined triangle: %d faces, %d nodes", len(s.Faces()), s.Nodes().Len())
+	}
+	if p := point(s.Node(0).(Node)); !closeTo(p, Point{X: 1, Y: 1}) {
+		t.Errorf("dcel: wrong position of boundary node: %v", p)
+	}
+	for _, f := range s.Faces() {
+		if faceArea(s, f) <= 0 {
+			t.Errorf("dcel: face %d is not counterclockwise", f.ID())
+		}
+	}
+
+	quad := newMesh(rect(0, 0, 1, 1), [][]int{{0, 1, 2, 3}})
+	if _, err = quad.LoopSubdivide(1); err == nil {
+		t.Error("dcel: expected error for a quadrilateral")
+	}
+}
+
+func TestCatmullClark(t *testing.T) {
+	var corners []Point
+	for _, z := range []float64{-1, 1} {
+		for _, y := range []float64{-1, 1} {
+			for _, x := range []float64{-1, 1} {
+				corners = append(corners, Point{X: x, Y: y, Z: z})
+			}
+		}
+	}
+	cube := newMesh(corners, [][]int{
+		{0, 2, 3, 1}, {4, 5, 7, 6}, {0, 1, 5, 4}, {2, 6, 7, 3}, {0, 4, 6, 2}, {1, 3, 7, 5},
+	})
+	s, err := cube.CatmullClark(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkClosed(t, s, 24, 4)
+	if s.Nodes().Len() != 26 {
+		t.Errorf("dcel: wrong number of nodes: %d", s.Nodes().Len())
+	}
+	if p := point(s.Node(7).(Node)); !closeTo(p, Point{X: 5.0 / 9, Y: 5.0 / 9, Z: 5.0 / 9}) {
+		t.Errorf("dcel: wrong position of corner node: %v", p)
+	}
+	for _, u := range graph.NodesOf(s.Nodes()) {
+		p := point(u.(Node))
+		if n := p.Norm(); n >= math.Sqrt(3) || n < 5.0/9 {
+			t.Errorf("dcel: node %d out of range: %v", u.ID(), p)
+		}
+	}
+	s, err = cube.CatmullClark(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkClosed(t, s, 96, 4)
+
+	// A triangle and a square sharing an edge.
+	g := newMesh([]Point{{X: 0}, {X: 4}, {X: 4, Y: 4}, {Y: 4}, {X: 2, Y: -4}},
+		[][]int{{0, 1, 2, 3}, {0, 4, 1}})
+	s, err = g.CatmullClark(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Faces()) != 7 {
+		t.Errorf("dcel: wrong number of faces: %d", len(s.Faces()))
+	}
+	if p := point(s.Node(3).(Node)); !closeTo(p, Point{X: 0.5, Y: 3.5}) {
+		t.Errorf("dcel: wrong position of boundary node: %v", p)
+	}
+	for _, f := range s.Faces() {
+		if faceArea(s, f) <= 0 {
+			t.Errorf("dcel: face %d is not counterclockwise", f.ID())
+		}
+	}
+}
+
+func TestSqrt3(t *testing.T) {
+	tetrahedron := newMesh(
+		[]Point{{X: 1, Y: 1, Z: 1}, {X: 1, Y: -1, Z: -1}, {X: -1, Y: 1, Z: -1}, {X: -1, Y: -1, Z: 1}},
+		[][]int{{0, 1, 2}, {0, 3, 1}, {0, 2, 3}, {1, 3, 2}},
+	)
+	s, err := tetrahedron.Sqrt3(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkClosed(t, s, 12, 3)
+	for id := int64(0); id < 8; id++ {
+		if s.From(id).Len() != 3 && s.From(id).Len() != 6 {
+			t.Errorf("dcel: wrong degree of node %d: %d", id, s.From(id).Len())
+		}
+	}
+	s, err = tetrahedron.Sqrt3(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkClosed(t, s, 36, 3)
+
+	// Two triangles forming a square. The boundary nodes keep their
+	// positions and the boundary edges are not refined.
+	square := newMesh(rect(0, 0, 2, 2), [][]int{{0, 1, 2}, {0, 2, 3}})
+	s, err = square.Sqrt3(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Faces()) != 6 || s.Nodes().Len() != 6 {
+		t.Errorf("dcel: wrong size of refined square: %d faces, %d nodes", len(s.Faces()), s.Nodes().Len())
+	}
+	if s.HasEdgeBetween(0, 2) || !s.HasEdgeBetween(4, 5) {
+		t.Error("dcel: interior edge not flipped")
+	}
+	var total float64
+	for _, f := range s.Faces() {
+		a := faceArea(s, f)
+		if a <= 0 {
+			t.Errorf("dcel: face %d is not counterclockwise", f.ID())
+		}
+		total += a
+	}
+	if math.Abs(total-4) > 1e-12 {
+		t.Errorf("dcel: wrong area: %v", total)
+	}
+
+	if _, err = newMesh(rect(0, 0, 1, 1), [][]int{{0, 1, 2, 3}}).Sqrt3(1); err == nil {
+		t.Error("dcel: expected error for a quadrilateral")
+	}
+}
@@ -0,0 +1,128 @@
+package dcel
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Subgraph returns a new graph with copies of the given faces of g and of
+// their nodes and edges, and the map from the IDs in g to the IDs of the
+// copies, which are the same. The new graph is allocated with items and has
+// the weight function of g. If items is nil, the Items of g will be used.
+//
+// Only the selected part of g is visited. Where the selection ends, the copies
+// of halfedges of the selected faces have twins without a face that form the
+// boundary loops of the subgraph. The positions of nodes and the weights of
+// edges that store them are copied but other data of the elements, their
+// property values and the observers of g are not.
+//
+// Subgraph panics if a face does not belong to g.
+func (g *Graph) Subgraph(faces []Face, items Items) (*Graph, IDMap) {
+	selected := make(map[Face]bool, len(faces))
+	for _, f := range faces {
+		if g.faces[f.ID()] != f {
+			panic(fmt.Sprintf("dcel: face %d not in graph", f.ID()))
+		}
+		selected[f] = true
+	}
+
+	if items == nil {
+		items = g.items
+	}
+	sub := NewWeighted(items, g.weight)
+	m := IDMap{
+		Nodes: make(map[int64]int64),
+		Edges: make(map[int64]int64),
+		Faces: make(map[int64]int64),
+	}
+	faceIDs := make([]int64, 0, len(selected))
+	for f := range selected {
+		faceIDs = append(faceIDs, f.ID())
+	}
+	sort.Slice(faceIDs, func(i, j int) bool { return faceIDs[i] < faceIDs[j] })
+
+	// Allocate the copies.
+	var (
+		nodes  = make(map[int64]Node)
+		copies = make(map[int64]Face, len(faceIDs))
+		hedges = make(map[Halfedge]Halfedge)
+		edges  []Edge
+	)
+	for _, id := range faceIDs {
+		copies[id] = sub.items.NewFace(id)
+		m.Faces[id] = id
+		for _, h := range g.HalfedgesAround(g.faces[id]) {
+			if _, ok := hedges[h]; ok {
+				continue
+			}
+			for _, u := range [2]Node{h.From(), h.Twin().From()} {
+				if _, ok := nodes[u.ID()]; ok {
+					continue
+				}
+				v := sub.AddNode(u.ID())
+				if p, ok := position(u); ok {
+					setPoint(v, p)
+				}
+				nodes[u.ID()] = v
+				m.Nodes[u.ID()] = u.ID()
+			}
+			e := sub.newEdge(h.Edge().ID())
+			if we, ok := e.(WeightedEdge); ok {
+				we.SetWeight(h.Edge().Weight())
+			}
+			h1, h2 := h.Edge().Halfedges()
+			c1, c2 := e.Halfedges()
+			hedges[h1] = c1
+			hedges[h2] = c2
+			edges = append(edges, e)
+			m.Edges[e.ID()] = e.ID()
+		}
+	}
+
+	// Connect the copies. The halfedges of selected faces keep their
+	// connections. The next halfedge of a boundary halfedge is found by
+	// rotating around its end node to the first outgoing halfedge that has a
+	// copy, which lies in the same gap between selected faces.
+	for h, c := range hedges {
+		c.SetFrom(nodes[h.From().ID()])
+		if f := h.Face(); selected[f] {
+			c.SetFace(copies[f.ID()])
+			c.SetNext(hedges[h.Next()])
+			hedges[h.Next()].SetPrev(c)
+			continue
+		}
+		next := h.Next()
+		for {
+			if _, ok := hedges[next]; ok {
+				break
+			}
+			next = next.Twin().Next()
+		}
+		c.SetNext(hedges[next])
+		hedges[next].SetPrev(c)
+	}
+	for id, v := range nodes {
+		start := g.nodes[id].Halfedge()
+		for h := start; ; h = h.Twin().Next() {
+			if c, ok := hedges[h]; ok {
+				v.SetHalfedge(c)
+				break
+			}
+		}
+		if h := freeHalfedge(v); h != nil {
+			v.SetHalfedge(h)
+		}
+	}
+	for _, id := range faceIDs {
+		copies[id].SetHalfedge(hedges[g.faces[id].Halfedge()])
+	}
+
+	sort.Slice(edges, func(i, j int) bool { return edges[i].ID() < edges[j].ID() })
+	for _, e := range edges {
+		sub.insertEdge(e)
+	}
+	for _, id := range faceIDs {
+		sub.insertFace(copies[id])
+	}
+	return sub, m
+}
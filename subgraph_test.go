@@ -0,0 +1,70 @@
+package dcel
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSubgraph(t *testing.T) {
+	// Four squares with nodes 0 to 8 where node 2 is the center.
+	g := newPolygons(rect(0, 0, 1, 1), rect(1, 0, 2, 1), rect(0, 1, 1, 2), rect(1, 1, 2, 2))
+
+	g.Halfedge(0, 1).Edge().(WeightedEdge).SetWeight(5)
+	sub, m := g.Subgraph([]Face{g.Face(1), g.Face(0)}, nil)
+	checkLinks(t, sub)
+	if !reflect.DeepEqual(m.Faces, map[int64]int64{0: 0, 1: 1}) || len(m.Nodes) != 6 || len(m.Edges) != 7 {
+		t.Errorf("dcel: wrong ID map: %+v", m)
+	}
+	for id := range m.Edges {
+		if sub.edges[id] == nil || sub.edges[id].Weight() != g.edges[id].Weight() {
+			t.Errorf("dcel: edge %d not copied", id)
+		}
+	}
+	if w, _ := sub.Weight(0, 1); w != 5 {
+		t.Errorf("dcel: wrong weight of copied edge: got %v, want 5", w)
+	}
+	loops := sub.BoundaryLoops()
+	if len(loops) != 1 || len(sub.Loop(loops[0])) != 6 {
+		t.Error("dcel: wrong boundary of subgraph")
+	}
+	for _, f := range sub.Faces() {
+		if faceArea(sub, f) != 1 || faceArea(g, g.Face(f.ID())) != 1 {
+			t.Errorf("dcel: face %d not copied", f.ID())
+		}
+	}
+	for _, id := range sub.nodeIDs() {
+		if u := sub.nodes[id]; u.Halfedge().Face() != nil || point(u) != point(g.nodes[id]) {
+			t.Errorf("dcel: wrong copy of node %d", id)
+		}
+	}
+	if len(g.Faces()) != 4 || len(g.BoundaryLoops()) != 1 {
+		t.Error("dcel: graph modified")
+	}
+
+	// Squares touching only at the center form two fans around it.
+	sub, _ = g.Subgraph([]Face{g.Face(0), g.Face(3)}, PointBase{})
+	checkLinks(t, sub)
+	var ids []int64
+	for _, u := range sub.NonManifoldNodes() {
+		ids = append(ids, u.ID())
+	}
+	if !reflect.DeepEqual(ids, []int64{2}) {
+		t.Errorf("dcel: wrong non-manifold nodes of subgraph: %v", ids)
+	}
+	if topo := sub.Topology(); topo.Nodes != 7 || topo.Edges != 8 || topo.BoundaryLoops != 1 {
+		t.Errorf("dcel: wrong topology of subgraph: %+v", topo)
+	}
+
+	cube := newTriangulatedCube()
+	sub, _ = cube.Subgraph(cube.Faces(), nil)
+	checkClosed(t, sub, 12, 3)
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("dcel: expected panic for a face of another graph")
+			}
+		}()
+		g.Subgraph([]Face{g.Face(0), cube.Face(1)}, nil)
+	}()
+}
@@ -0,0 +1,304 @@
+package dcel
+
+import (
+	"container/heap"
+	"math/big"
+	"sort"
+)
+
+// sweep holds the state of the plane sweep of Bentley and Ottmann that finds
+// the intersections between the edges of the input graphs of an overlay. The
+// sweep line is vertical and moves from left to right. Events at the same X
+// coordinate are processed from bottom to top.
+type sweep struct {
+	// queue holds the pending events ordered by their points.
+	queue sweepEvents
+	// events holds the events keyed by their points so that each point is
+	// processed once.
+	events map[sweepKey]*sweepEvent
+	// status holds the segments that cross the sweep line ordered by the Y
+	// coordinate of their intersection with it.
+	status *sweepNode
+	// prio is the state of the generator of the priorities of status nodes.
+	prio uint64
+}
+
+// sweepPoint is the point of an event of the sweep. The point of an
+// intersection of two edges is also given by its exact rational coordinates
+// unless they are representable by float64, so that the order of the events
+// and the position of the point relative to the edges is exact and edges that
+// cross at the same point produce the same event.
+type sweepPoint struct {
+	p Point
+	// x and y are the exact coordinates of the point or nil if p is exact.
+	x, y *big.Rat
+}
+
+// sweepKey identifies the point of an event.
+type sweepKey struct {
+	p    Point
+	x, y string
+}
+
+// sweepEvent is an event of the sweep.
+type sweepEvent struct {
+	pt sweepPoint
+	// start holds the segments whose left end is the point of the event.
+	start []*overlaySegment
+}
+
+// sweepNode is a node of the treap that holds the status of the sweep.
+type sweepNode struct {
+	s           *overlaySegment
+	prio        uint64
+	left, right *sweepNode
+}
+
+// key returns the key identifying p.
+func (p sweepPoint) key() sweepKey {
+	if p.x == nil {
+		return sweepKey{p: p.p}
+	}
+	return sweepKey{p: p.p, x: p.x.RatString(), y: p.y.RatString()}
+}
+
+// coords returns the exact coordinates of p.
+func (p sweepPoint) coords() (x, y *big.Rat) {
+	if p.x == nil {
+		return rat(p.p.X), rat(p.p.Y)
+	}
+	return p.x, p.y
+}
+
+// compare returns the signs of the differences between the X and the Y
+// coordinates of p and q.
+func (p sweepPoint) compare(q sweepPoint) (cx, cy int) {
+	if p.x == nil && q.x == nil {
+		return sign(p.p.X - q.p.X), sign(p.p.Y - q.p.Y)
+	}
+	px, py := p.coords()
+	qx, qy := q.coords()
+	return px.Cmp(qx), py.Cmp(qy)
+}
+
+// less returns whether p precedes q in the order of the sweep.
+func (p sweepPoint) less(q sweepPoint) bool {
+	cx, cy := p.compare(q)
+	return cx < 0 || (cx == 0 && cy < 0)
+}
+
+// side returns a positive value if p lies to the left of the line from a to b,
+// a negative value if it lies to the right, and zero if it lies on the line.
+// The sign of the result is exact.
+func (p sweepPoint) side(a, b Point) float64 {
+	return orientSweep(sweepPoint{p: a}, sweepPoint{p: b}, p)
+}
+
+// orientSweep returns a positive value if the points a, b and c are in
+// counterclockwise order, a negative value if they are in clockwise order, and
+// zero if they are collinear. The sign of the result is exact.
+func orientSweep(a, b, c sweepPoint) float64 {
+	if a.x == nil && b.x == nil && c.x == nil {
+		return orient(a.p, b.p, c.p)
+	}
+	ax, ay := a.coords()
+	bx, by := b.coords()
+	cx, cy := c.coords()
+	ax = new(big.Rat).Sub(ax, cx)
+	ay = new(big.Rat).Sub(ay, cy)
+	bx = new(big.Rat).Sub(bx, cx)
+	by = new(big.Rat).Sub(by, cy)
+	det := new(big.Rat).Mul(ax, by)
+	det.Sub(det, new(big.Rat).Mul(ay, bx))
+	return float64(det.Sign())
+}
+
+// sign returns the sign of x.
+func sign(x float64) int {
+	switch {
+	case x < 0:
+		return -1
+	case x > 0:
+		return 1
+	}
+	return 0
+}
+
+// crossing returns the intersection point of the segments s and t that cross
+// at a single point in the interior of both.
+func crossing(s, t *overlaySegment) sweepPoint {
+	ax, ay := rat(s.a.X), rat(s.a.Y)
+	dx := new(big.Rat).Sub(rat(s.b.X), ax)
+	dy := new(big.Rat).Sub(rat(s.b.Y), ay)
+	ex := new(big.Rat).Sub(rat(t.b.X), rat(t.a.X))
+	ey := new(big.Rat).Sub(rat(t.b.Y), rat(t.a.Y))
+	fx := new(big.Rat).Sub(rat(t.a.X), ax)
+	fy := new(big.Rat).Sub(rat(t.a.Y), ay)
+
+	// The intersection is at a + tau*d where tau = (f×e)/(d×e).
+	tau := new(big.Rat).Mul(fx, ey)
+	tau.Sub(tau, new(big.Rat).Mul(fy, ex))
+	den := new(big.Rat).Mul(dx, ey)
+	den.Sub(den, new(big.Rat).Mul(dy, ex))
+	tau.Quo(tau, den)
+
+	x := ax.Add(ax, dx.Mul(dx, tau))
+	y := ay.Add(ay, dy.Mul(dy, tau))
+	xf, xExact := x.Float64()
+	yf, yExact := y.Float64()
+	if xExact && yExact {
+		return sweepPoint{p: Point{X: xf, Y: yf}}
+	}
+	return sweepPoint{p: Point{X: xf, Y: yf}, x: x, y: y}
+}
+
+// event returns the event at the point p, adding it to the queue if it does
+// not exist.
+func (sw *sweep) event(p sweepPoint) *sweepEvent {
+	key := p.key()
+	e, ok := sw.events[key]
+	if !ok {
+		e = &sweepEvent{pt: p}
+		sw.events[key] = e
+		heap.Push(&sw.queue, e)
+	}
+	return e
+}
+
+// run processes the events of the sweep and calls fn with the point of each
+// event and the segments that contain the point in their interior.
+func (sw *sweep) run(fn func(p sweepPoint, inner []*overlaySegment)) {
+	for sw.queue.Len() > 0 {
+		e := heap.Pop(&sw.queue).(*sweepEvent)
+		r := e.pt
+
+		// The segments that contain r form a contiguous part of the status
+		// between the segments below r and the segments above r.
+		below, rest := splitStatus(sw.status, func(s *overlaySegment) bool { return r.side(s.a, s.b) > 0 })
+		through, above := splitStatus(rest, func(s *overlaySegment) bool { return r.side(s.a, s.b) == 0 })
+
+		var inner []*overlaySegment
+		walkStatus(through, func(s *overlaySegment) {
+			if r.x != nil || s.b != r.p {
+				inner = append(inner, s)
+			}
+		})
+		if len(inner) > 0 {
+			fn(r, inner)
+		}
+
+		// Insert the segments that continue to the right of r in the order
+		// of their directions, from the lowest to the highest.
+		next := append(inner, e.start...)
+		sort.SliceStable(next, func(i, j int) bool { return r.side(next[i].b, next[j].b) > 0 })
+		var mid *sweepNode
+		for _, s := range next {
+			sw.prio = sw.prio*6364136223846793005 + 1442695040888963407
+			mid = mergeStatus(mid, &sweepNode{s: s, prio: sw.prio})
+		}
+
+		// Test the new pairs of neighbors for intersections right of r.
+		lo, hi := lastStatus(below), firstStatus(above)
+		if mid == nil {
+			sw.cross(lo, hi, r)
+		} else {
+			sw.cross(lo, firstStatus(mid), r)
+			sw.cross(lastStatus(mid), hi, r)
+		}
+		sw.status = mergeStatus(mergeStatus(below, mid), above)
+	}
+}
+
+// cross adds the event at the intersection of the segments s and t if they
+// belong to different input graphs and cross right of r.
+func (sw *sweep) cross(s, t *overlaySegment, r sweepPoint) {
+	if s == nil || t == nil || s.in == t.in {
+		return
+	}
+	if !opposite(orient(s.a, s.b, t.a), orient(s.a, s.b, t.b)) ||
+		!opposite(orient(t.a, t.b, s.a), orient(t.a, t.b, s.b)) {
+		// The segments do not cross in their interiors. Contacts at their
+		// ends are found by the events at the ends.
+		return
+	}
+	if p := crossing(s, t); r.less(p) {
+		sw.event(p)
+	}
+}
+
+// splitStatus splits the treap t into the segments for which before returns
+// true and the rest. before must return true for a prefix of the segments.
+func splitStatus(t *sweepNode, before func(*overlaySegment) bool) (l, r *sweepNode) {
+	if t == nil {
+		return nil, nil
+	}
+	if before(t.s) {
+		t.right, r = splitStatus(t.right, before)
+		return t, r
+	}
+	l, t.left = splitStatus(t.left, before)
+	return l, t
+}
+
+// mergeStatus returns the treap of the segments of l followed by the segments
+// of r.
+func mergeStatus(l, r *sweepNode) *sweepNode {
+	if l == nil {
+		return r
+	}
+	if r == nil {
+		return l
+	}
+	if l.prio > r.prio {
+		l.right = mergeStatus(l.right, r)
+		return l
+	}
+	r.left = mergeStatus(l, r.left)
+	return r
+}
+
+// walkStatus calls fn with the segments of the treap t in order.
+func walkStatus(t *sweepNode, fn func(*overlaySegment)) {
+	if t == nil {
+		return
+	}
+	walkStatus(t.left, fn)
+	fn(t.s)
+	walkStatus(t.right, fn)
+}
+
+// firstStatus returns the first segment of the treap t or nil if t is empty.
+func firstStatus(t *sweepNode) *overlaySegment {
+	if t == nil {
+		return nil
+	}
+	for t.left != nil {
+		t = t.left
+	}
+	return t.s
+}
+
+// lastStatus returns the last segment of the treap t or nil if t is empty.
+func lastStatus(t *sweepNode) *overlaySegment {
+	if t == nil {
+		return nil
+	}
+	for t.right != nil {
+		t = t.right
+	}
+	return t.s
+}
+
+// sweepEvents is a min-heap of events ordered by their points.
+type sweepEvents []*sweepEvent
+
+func (q sweepEvents) Len() int            { return len(q) }
+func (q sweepEvents) Less(i, j int) bool  { return q[i].pt.less(q[j].pt) }
+func (q sweepEvents) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *sweepEvents) Push(x interface{}) { *q = append(*q, x.(*sweepEvent)) }
+func (q *sweepEvents) Pop() interface{} {
+	old := *q
+	x := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return x
+}
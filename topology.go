@@ -0,0 +1,139 @@
+package dcel
+
+// Topology summarizes the topology of a graph.
+type Topology struct {
+	// Nodes, Edges and Faces are the numbers of nodes, edges and faces in
+	// the graph.
+	Nodes, Edges, Faces int
+	// BoundaryLoops is the number of boundary loops in the graph.
+	BoundaryLoops int
+	// EulerCharacteristic is Nodes - Edges + Faces.
+	EulerCharacteristic int
+
+	// Components holds the summaries of the connected components of the
+	// graph ordered by the smallest node ID in the component.
+	Components []Component
+}
+
+// Component summarizes the topology of a connected component of a graph.
+//
+// The faces of a Graph are always oriented consistently, because each edge is
+// traversed by its adjacent faces in opposite directions, so every component
+// is orientable. Faces of a non-orientable surface cannot be added to a Graph;
+// OrientConsistently reports the components of a polygon soup that cannot be
+// oriented.
+type Component struct {
+	// Nodes, Edges and Faces are the numbers of nodes, edges and faces in
+	// the component.
+	Nodes, Edges, Faces int
+	// BoundaryLoops is the number of boundary loops in the component.
+	BoundaryLoops int
+	// EulerCharacteristic is Nodes - Edges + Faces.
+	EulerCharacteristic int
+	// Genus is the genus of the surface formed by the component. It is valid
+	// only if Manifold is true.
+	Genus int
+
+	// Closed is true if the component has no boundary loops.
+	Closed bool
+	// Manifold is true if the component is a two-manifold surface, possibly
+	// with boundary. That is, if every edge has at least one adjacent face
+	// and the faces around every node form a single fan.
+	Manifold bool
+}
+
+// Topology returns a summary of the topology of the graph.
+func (g *Graph) Topology() Topology {
+	t := Topology{
+		Nodes: len(g.nodes),
+		Edges: len(g.edges),
+		Faces: len(g.faces),
+	}
+	t.EulerCharacteristic = t.Nodes - t.Edges + t.Faces
+
+	visited := make(map[int64]struct{})
+	for _, id := range g.nodeIDs() {
+		if _, ok := visited[id]; ok {
+			continue
+		}
+		c := g.component(g.nodes[id], visited)
+		t.BoundaryLoops += c.BoundaryLoops
+		t.Components = append(t.Components, c)
+	}
+	return t
+}
+
+// component returns the summary of the connected component that contains u.
+// The nodes of the component are added to visited.
+func (g *Graph) component(u Node, visited map[int64]struct{}) Component {
+	c := Component{Manifold: true}
+	var (
+		hedges int
+		faces  = make(map[int64]struct{})
+		seen   = make(map[Halfedge]struct{})
+		queue  = []Node{u}
+	)
+	visited[u.ID()] = struct{}{}
+	for len(queue) > 0 {
+		u := queue[0]
+		queue = queue[1:]
+		c.Nodes++
+		if !manifoldNode(u) {
+			c.Manifold = false
+		}
+		for _, h := range g.HalfedgesFrom(u.ID()) {
+			hedges++
+			if f := h.Face(); f != nil {
+				faces[f.ID()] = struct{}{}
+			} else {
+				if h.Twin().Face() == nil {
+					// The edge has no adjacent face.
+					c.Manifold = false
+				}
+				if _, ok := seen[h]; !ok {
+					for _, lh := range g.Loop(h) {
+						seen[lh] = struct{}{}
+					}
+					c.BoundaryLoops++
+				}
+			}
+			v := h.Twin().From()
+			if _, ok := visited[v.ID()]; ok {
+				continue
+			}
+			visited[v.ID()] = struct{}{}
+			queue = append(queue, v)
+		}
+	}
+	c.Faces = len(faces)
+	if c.Faces == 0 {
+		c.Manifold = false
+	}
+	c.Edges = hedges / 2
+	c.EulerCharacteristic = c.Nodes - c.Edges + c.Faces
+	c.Closed = c.BoundaryLoops == 0
+	if c.Manifold {
+		c.Genus = (2 - c.EulerCharacteristic - c.BoundaryLoops) / 2
+	}
+	return c
+}
+
+// manifoldNode returns whether the faces around u form at most one fan.
+func manifoldNode(u Node) bool {
+	if u.Halfedge() == nil {
+		return true
+	}
+	// Each gap between fans around u starts with an outgoing halfedge without
+	// an adjacent face.
+	var gaps int
+	for h := u.Halfedge(); ; {
+		if h.Face() == nil {
+			gaps++
+		}
+		h = h.Twin().Next()
+		if h == u.Halfedge() {
+			break
+		}
+	}
+	return gaps <= 1
+}
@@ -0,0 +1,109 @@
+package dcel
+
+import "testing"
+
+// newTorus returns a graph with a torus formed by n×m quadrilaterals.
+func newTorus(n, m int) *Graph {
+	g := New(nil)
+	for i := 0; i < n; i++ {
+		for j := 0; j < m; j++ {
+			i1, j1 := (i+1)%n, (j+1)%m
+			err := g.AddFace(g.NewFaceID(),
+				NodeID(i*m+j), NodeID(i1*m+j), NodeID(i1*m+j1), NodeID(i*m+j1))
+			if err != nil {
+				panic(err)
+			}
+		}
+	}
+	return g
+}
+
+// newTetrahedron returns a graph with the surface of a tetrahedron.
+func newTetrahedron() *Graph {
+	g := New(nil)
+	for i, f := range [][]int{{0, 2, 1}, {0, 1, 3}, {1, 2, 3}, {2, 0, 3}} {
+		err := g.AddFace(int64(i), NodeID(f[0]), NodeID(f[1]), NodeID(f[2]))
+		if err != nil {
+			panic(err)
+		}
+	}
+	return g
+}
+
+func TestTopology(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		g     *Graph
+		euler int
+		want  []Component
+	}{
+		{
+			name:  "tetrahedron",
+			g:     newTetrahedron(),
+			euler: 2,
+			want: []Component{
+				{Nodes: 4, Edges: 6, Faces: 4, EulerCharacteristic: 2, Genus: 0, Closed: true, Manifold: true},
+			},
+		},
+		{
+			name:  "square",
+			g:     newSquare(nil),
+			euler: 1,
+			want: []Component{
+				{Nodes: 4, Edges: 5, Faces: 2, BoundaryLoops: 1, EulerCharacteristic: 1, Genus: 0, Manifold: true},
+			},
+		},
+		{
+			name:  "torus",
+			g:     newTorus(3, 4),
+			euler: 0,
+			want: []Component{
+				{Nodes: 12, Edges: 24, Faces: 12, EulerCharacteristic: 0, Genus: 1, Closed: true, Manifold: true},
+			},
+		},
+	} {
+		top := test.g.Topology()
+		if top.EulerCharacteristic != test.euler {
+			t.Errorf("dcel: %s: wrong Euler characteristic: got %d, want %d",
+				test.name, top.EulerCharacteristic, test.euler)
+		}
+		if len(top.Components) != len(test.want) {
+			t.Errorf("dcel: %s: wrong number of components: got %d, want %d",
+				test.name, len(top.Components), len(test.want))
+			continue
+		}
+		for i, c := range top.Components {
+			if c != test.want[i] {
+				t.Errorf("dcel: %s: wrong component %d: got %+v, want %+v",
+					test.name, i, c, test.want[i])
+			}
+		}
+	}
+}
+
+func TestTopologyNonManifold(t *testing.T) {
+	g := New(nil)
+	// Two triangles sharing only node 0.
+	if err := g.AddFace(0, NodeID(0), NodeID(1), NodeID(2)); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.AddFace(1, NodeID(0), NodeID(3), NodeID(4)); err != nil {
+		t.Fatal(err)
+	}
+	g.AddNode(5)
+
+	top := g.Topology()
+	if len(top.Components) != 2 {
+		t.Fatalf("dcel: wrong number of components: %d", len(top.Components))
+	}
+	if top.Components[0].Manifold {
+		t.Error("dcel: bow-tie reported as manifold")
+	}
+	// The boundary loop passes twice through node 0.
+	if top.Components[0].BoundaryLoops != 1 {
+		t.Errorf("dcel: wrong number of boundary loops: %d", top.Components[0].BoundaryLoops)
+	}
+	if top.Components[1].Nodes != 1 || top.Components[1].Manifold {
+		t.Error("dcel: wrong isolated node component")
+	}
+}
@@ -0,0 +1,386 @@
+package dcel
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"gonum.org/v1/gonum/graph"
+)
+
+// TriangulateFace splits the face f into triangles by adding diagonals between
+// its nodes and returns the triangles. The first returned triangle is f itself
+// with the same ID and property values. If f is already a triangle, it is
+// returned unchanged.
+//
+// If all nodes of f carry positions, the face is triangulated by ear clipping
+// in the plane that best fits the nodes, so that a face that is a simple
+// polygon is split into triangles that do not overlap. Otherwise the face is
+// split into a fan of triangles around one of its nodes.
+//
+// Diagonals that would duplicate an existing edge of the graph are not used.
+// If f cannot be triangulated without them or if f is not in the graph, an
+// error is returned and the graph is not modified. If a triangle cannot be
+// added, for example because a node of f is not manifold, the triangles added
+// so far and their diagonals are removed, f is added back with its property
+// values and the error from AddFace is returned.
+func (g *Graph) TriangulateFace(f Face) ([]Face, error) {
+	if g.faces[f.ID()] != f {
+		return nil, fmt.Errorf("dcel: face %d not in graph", f.ID())
+	}
+	hedges := g.HalfedgesAround(f)
+	if len(hedges) == 3 {
+		return []Face{f}, nil
+	}
+	nodes := make([]Node, len(hedges))
+	for i, h := range hedges {
+		nodes[i] = h.From()
+	}
+
+	var (
+		tris [][3]int
+		ok   bool
+	)
+	if pts, has := positions(nodes); has {
+		tris, ok = g.earClip(nodes, pts)
+	} else {
+		tris, ok = g.fan(nodes)
+	}
+	if !ok {
+		return nil, fmt.Errorf("dcel: cannot triangulate face %d without duplicating an edge", f.ID())
+	}
+
+	id := f.ID()
+	restore := g.props.saveFace(id)
+	g.RemoveFace(f)
+	faces := make([]Face, len(tris))
+	for i, t := range tris {
+		tid, tf := id, f
+		if i > 0 {
+			tid, tf = g.NewFaceID(), nil
+		}
+		err := g.addFace(tid, tf, []graph.Node{nodes[t[0]], nodes[t[1]], nodes[t[2]]})
+		if err != nil {
+			g.untriangulate(f, nodes, tris, faces[:i])
+			restore()
+			return nil, err
+		}
+		faces[i] = g.faces[tid]
+	}
+	restore()
+	return faces, nil
+}
+
+// untriangulate removes the triangles added so far and the diagonals between
+// nodes when the triangulation of f into tris fails, and adds f back. The
+// diagonals did not exist before the triangulation. The triangles and the
+// diagonals are removed in the reverse order of their IDs so that the ID
+// counters are restored.
+func (g *Graph) untriangulate(f Face, nodes []Node, tris [][3]int, added []Face) {
+	for i := len(added) - 1; i >= 0; i-- {
+		g.RemoveFace(added[i])
+	}
+	var (
+		diagonals []Halfedge
+		seen      = make(map[Edge]bool)
+		n         = len(nodes)
+	)
+	for _, t := range tris {
+		for k := range t {
+			i, j := t[k], t[(k+1)%3]
+			if (i+1)%n == j || (j+1)%n == i {
+				// A side of f.
+				continue
+			}
+			if h := g.Halfedge(nodes[i].ID(), nodes[j].ID()); h != nil && !seen[h.Edge()] {
+				seen[h.Edge()] = true
+				diagonals = append(diagonals, h)
+			}
+		}
+	}
+	sort.Slice(diagonals, func(i, j int) bool { return diagonals[i].Edge().ID() > diagonals[j].Edge().ID() })
+	for _, h := range diagonals {
+		g.removeEdge(h)
+	}
+	if err := g.addFace(f.ID(), f, toGraphNodes(nodes)); err != nil {
+		// This would be our bug.
+		panic(err)
+	}
+}
+
+// TriangulateAll splits every face of the graph that is not a triangle into
+// triangles using TriangulateFace. If a face cannot be triangulated, an error
+// is returned and the faces that have already been triangulated are kept.
+func (g *Graph) TriangulateAll() error {
+	for _, id := range g.faceIDs() {
+		if _, err := g.TriangulateFace(g.faces[id]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// earClip returns the triangles of the polygon with nodes and their positions
+// pts computed by ear clipping. The polygon is projected onto the coordinate
+// plane orthogonal to the dominant axis of its Newell normal. The triangles are
+// given by indices into nodes.
+func (g *Graph) earClip(nodes []Node, pts []Point) ([][3]int, bool) {
+	var normal Point
+	for i, p := range pts {
+		q := pts[(i+1)%len(pts)]
+		normal.X += (p.Y - q.Y) * (p.Z + q.Z)
+		normal.Y += (p.Z - q.Z) * (p.X + q.X)
+		normal.Z += (p.X - q.X) * (p.Y + q.Y)
+	}
+	if normal == (Point{}) {
+		// The polygon is degenerate.
+		return g.fan(nodes)
+	}
+
+	// Project the polygon so that it is counterclockwise in the XY plane.
+	ax, ay, az := math.Abs(normal.X), math.Abs(normal.Y), math.Abs(normal.Z)
+	proj := make([]Point, len(pts))
+	for i, p := range pts {
+		switch {
+		case az >= ax && az >= ay && normal.Z > 0:
+			proj[i] = Point{X: p.X, Y: p.Y}
+		case az >= ax && az >= ay:
+			proj[i] = Point{X: p.Y, Y: p.X}
+		case ay >= ax && normal.Y > 0:
+			proj[i] = Point{X: p.Z, Y: p.X}
+		case ay >= ax:
+			proj[i] = Point{X: p.X, Y: p.Z}
+		case normal.X > 0:
+			proj[i] = Point{X: p.Y, Y: p.Z}
+		default:
+			proj[i] = Point{X: p.Z, Y: p.Y}
+		}
+	}
+
+	simple := isSimple(proj)
+	poly := make([]int, len(pts))
+	for i := range poly {
+		poly[i] = i
+	}
+	var tris [][3]int
+	for len(poly) > 3 {
+		m := len(poly)
+		// Find an ear. If there is none in a simple polygon, the diagonals of
+		// all ears already exist and another triangulation must be searched
+		// for. If there is none because the polygon is not simple, clip a
+		// convex node or any node to make progress.
+		ear, convex, other := -1, -1, -1
+		for k := range poly {
+			i0, i1, i2 := poly[(k+m-1)%m], poly[k], poly[(k+1)%m]
+			if g.HasEdgeBetween(nodes[i0].ID(), nodes[i2].ID()) {
+				continue
+			}
+			if other < 0 {
+				other = k
+			}
+			a, b, c := proj[i0], proj[i1], proj[i2]
+			if orient(a, b, c) <= 0 {
+				continue
+			}
+			if convex < 0 {
+				convex = k
+			}
+			if isEar(a, b, c, poly, proj) {
+				ear = k
+				break
+			}
+		}
+		k := ear
+		if k < 0 && simple {
+			return g.triangulateSimple(nodes, proj)
+		}
+		if k < 0 {
+			k = convex
+		}
+		if k < 0 {
+			k = other
+		}
+		if k < 0 {
+			return nil, false
+		}
+		tris = append(tris, [3]int{poly[(k+m-1)%m], poly[k], poly[(k+1)%m]})
+		poly = append(poly[:k], poly[k+1:]...)
+	}
+	return append(tris, [3]int{poly[0], poly[1], poly[2]}), true
+}
+
+// triangulateSimple returns the triangles of a triangulation of the simple
+// counterclockwise polygon with nodes and projected positions proj that uses
+// only interior diagonals that are not edges of the graph, or false if there is
+// no such triangulation. The triangles are given by indices into nodes.
+func (g *Graph) triangulateSimple(nodes []Node, proj []Point) ([][3]int, bool) {
+	// Find the triangulation by dynamic programming. split[i][j] is the index
+	// of the third node of the triangle on the edge or diagonal between
+	// nodes[i] and nodes[j] in a triangulation of the polygon nodes[i], ...,
+	// nodes[j], or zero if there is none.
+	n := len(nodes)
+	split := make([][]int, n)
+	for i := range split {
+		split[i] = make([]int, n)
+	}
+	// side returns whether nodes[i] and nodes[j] with i < j can be joined by
+	// a side of a triangle.
+	side := func(i, j int) bool {
+		if j-i == 1 || (i == 0 && j == n-1) {
+			return true
+		}
+		return !g.HasEdgeBetween(nodes[i].ID(), nodes[j].ID()) && isDiagonal(i, j, proj)
+	}
+	for d := 2; d < n; d++ {
+		for i := 0; i+d < n; i++ {
+			j := i + d
+			if !side(i, j) {
+				continue
+			}
+			for k := i + 1; k < j; k++ {
+				if (k-i == 1 || split[i][k] != 0) && (j-k == 1 || split[k][j] != 0) {
+					split[i][j] = k
+					break
+				}
+			}
+		}
+	}
+	if split[0][n-1] == 0 {
+		return nil, false
+	}
+
+	var (
+		tris  [][3]int
+		stack = [][2]int{{0, n - 1}}
+	)
+	for len(stack) > 0 {
+		ij := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		i, j := ij[0], ij[1]
+		if j-i < 2 {
+			continue
+		}
+		k := split[i][j]
+		tris = append(tris, [3]int{i, k, j})
+		stack = append(stack, [2]int{i, k}, [2]int{k, j})
+	}
+	return tris, true
+}
+
+// isSimple returns whether the polygon with positions pts in the XY plane is
+// simple, that is whether its nodes are distinct and its edges meet only at the
+// shared nodes of consecutive edges.
+func isSimple(pts []Point) bool {
+	n := len(pts)
+	seen := make(map[[2]float64]bool, n)
+	for _, p := range pts {
+		key := [2]float64{p.X, p.Y}
+		if seen[key] {
+			return false
+		}
+		seen[key] = true
+	}
+	for i := 0; i < n; i++ {
+		a, b := pts[i], pts[(i+1)%n]
+		for j := i + 1; j < n; j++ {
+			c, d := pts[j], pts[(j+1)%n]
+			switch {
+			case j == i+1:
+				// The edges share b and overlap if they fold back.
+				if orient(a, b, d) == 0 && a.Sub(b).Dot(d.Sub(b)) > 0 {
+					return false
+				}
+			case i == 0 && j == n-1:
+				// The edges share a.
+				if orient(c, a, b) == 0 && c.Sub(a).Dot(b.Sub(a)) > 0 {
+					return false
+				}
+			case segmentsIntersect(a, b, c, d):
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// isDiagonal returns whether the segment between the nodes i and j of the
+// simple counterclockwise polygon with positions pts lies in the interior of
+// the polygon.
+func isDiagonal(i, j int, pts []Point) bool {
+	if !inCone(i, j, pts) || !inCone(j, i, pts) {
+		return false
+	}
+	n := len(pts)
+	for k := range pts {
+		l := (k + 1) % n
+		if k == i || k == j || l == i || l == j {
+			continue
+		}
+		if segmentsIntersect(pts[i], pts[j], pts[k], pts[l]) {
+			return false
+		}
+	}
+	return true
+}
+
+// inCone returns whether the segment from node i to node j of the
+// counterclockwise polygon with positions pts starts into the interior of the
+// polygon at node i.
+func inCone(i, j int, pts []Point) bool {
+	n := len(pts)
+	a, b := pts[i], pts[j]
+	prev, next := pts[(i+n-1)%n], pts[(i+1)%n]
+	if orient(prev, a, next) >= 0 {
+		// The polygon is convex at a.
+		return orient(a, b, prev) > 0 && orient(b, a, next) > 0
+	}
+	return !(orient(a, b, next) >= 0 && orient(b, a, prev) >= 0)
+}
+
+// segmentsIntersect returns whether the closed segments ab and cd in the XY
+// plane with distinct end points have a common point.
+func segmentsIntersect(a, b, c, d Point) bool {
+	o1, o2 := orient(a, b, c), orient(a, b, d)
+	o3, o4 := orient(c, d, a), orient(c, d, b)
+	if opposite(o1, o2) && opposite(o3, o4) {
+		return true
+	}
+	return (o1 == 0 && between(a, b, c)) || (o2 == 0 && between(a, b, d)) ||
+		(o3 == 0 && between(c, d, a)) || (o4 == 0 && between(c, d, b))
+}
+
+// isEar returns whether no node of the polygon poly with positions pts lies in
+// the counterclockwise triangle a, b, c.
+func isEar(a, b, c Point, poly []int, pts []Point) bool {
+	for _, i := range poly {
+		p := pts[i]
+		if samePosition(p, a) || samePosition(p, b) || samePosition(p, c) {
+			continue
+		}
+		if orient(a, b, p) >= 0 && orient(b, c, p) >= 0 && orient(c, a, p) >= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// fan returns the triangles of a fan triangulation of the polygon with nodes
+// around a node that is not connected by an edge to the other nodes of the
+// polygon except its neighbors. The triangles are given by indices into nodes.
+func (g *Graph) fan(nodes []Node) ([][3]int, bool) {
+	n := len(nodes)
+center:
+	for k, u := range nodes {
+		for i := 2; i < n-1; i++ {
+			if g.HasEdgeBetween(u.ID(), nodes[(k+i)%n].ID()) {
+				continue center
+			}
+		}
+		tris := make([][3]int, n-2)
+		for i := range tris {
+			tris[i] = [3]int{k, (k + i + 1) % n, (k + i + 2) % n}
+		}
+		return tris, true
+	}
+	return nil, false
+}
@@ -0,0 +1,219 @@
+package dcel
+
+import (
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/graph"
+)
+
+func TestTriangulateFace(t *testing.T) {
+	// A comb with teeth pointing up.
+	comb := []Point{{X: 0, Y: 0}, {X: 5, Y: 0}, {X: 5, Y: 2}, {X: 4, Y: 2}, {X: 4, Y: 1},
+		{X: 3, Y: 1}, {X: 3, Y: 2}, {X: 2, Y: 2}, {X: 2, Y: 1}, {X: 1, Y: 1}, {X: 1, Y: 2}, {X: 0, Y: 2}}
+	for _, test := range []struct {
+		name    string
+		project func(Point) Point
+		normal  Point
+	}{
+		{
+			name:    "xy",
+			project: func(p Point) Point { return p },
+			normal:  Point{Z: 1},
+		},
+		{
+			name:    "tilted",
+			project: func(p Point) Point { return Point{X: p.X, Y: 0.6 * p.Y, Z: 0.8 * p.Y} },
+			normal:  Point{Y: -0.8, Z: 0.6},
+		},
+		{
+			name:    "yz",
+			project: func(p Point) Point { return Point{X: 1, Y: -p.X, Z: p.Y} },
+			normal:  Point{X: -1},
+		},
+	} {
+		poly := make([]Point, len(comb))
+		for i, p := range comb {
+			poly[i] = test.project(p)
+		}
+		g := newPolygons(poly)
+		f := g.Face(0)
+		faces, err := g.TriangulateFace(f)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if len(faces) != len(comb)-2 || len(g.Faces()) != len(comb)-2 {
+			t.Errorf("%s: wrong number of triangles: %d", test.name, len(faces))
+		}
+		if faces[0] != f || g.Face(0) != f {
+			t.Errorf("%s: face not reused", test.name)
+		}
+		var total float64
+		for _, tf := range faces {
+			hedges := g.HalfedgesAround(tf)
+			if len(hedges) != 3 {
+				t.Fatalf("%s: face %d is not a triangle", test.name, tf.ID())
+			}
+			a, b, c := point(hedges[0].From()), point(hedges[1].From()), point(hedges[2].From())
+			n := b.Sub(a).Cross(c.Sub(a))
+			if n.Dot(test.normal) <= 0 {
+				t.Errorf("%s: triangle %d is not counterclockwise", test.name, tf.ID())
+			}
+			total += n.Norm() / 2
+		}
+		if math.Abs(total-8) > 1e-12 {
+			t.Errorf("%s: wrong total area of triangles: got %v, want 8", test.name, total)
+		}
+	}
+}
+
+func TestTriangulateFaceTopological(t *testing.T) {
+	g := New(Base{})
+	hexagon := make([]graph.Node, 6)
+	for i := range hexagon {
+		hexagon[i] = NodeID(i)
+	}
+	if err := g.AddFace(0, hexagon...); err != nil {
+		t.Fatal(err)
+	}
+	// Connect nodes 0 and 2 of the hexagon by an edge outside of it.
+	if err := g.AddFace(1, NodeID(6), NodeID(7), NodeID(8), NodeID(9)); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.AddFace(2, NodeID(2), NodeID(0), NodeID(10)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := g.TriangulateAll(); err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Faces()) != 7 {
+		t.Errorf("dcel: wrong number of faces: %d", len(g.Faces()))
+	}
+	for _, f := range g.Faces() {
+		if len(g.HalfedgesAround(f)) != 3 {
+			t.Errorf("dcel: face %d is not a triangle", f.ID())
+		}
+	}
+
+	// Both diagonals of the quadrilateral already exist.
+	g = New(Base{})
+	for _, face := range [][]graph.Node{
+		{NodeID(0), NodeID(1), NodeID(2), NodeID(3)},
+		{NodeID(2), NodeID(0), NodeID(4)},
+		{NodeID(3), NodeID(1), NodeID(5)},
+	} {
+		if err := g.AddFace(g.NewFaceID(), face...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := g.TriangulateFace(g.Face(0)); err == nil {
+		t.Error("dcel: expected error for quadrilateral with existing diagonals")
+	}
+	if len(g.HalfedgesAround(g.Face(0))) != 4 {
+		t.Error("dcel: face modified after failed triangulation")
+	}
+}
+
+func TestTriangulateFaceExistingDiagonals(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		poly     []Point
+		existing [][2]int
+		ok       bool
+	}{
+		{
+			// Ear clipping would clip nodes 0 and 1 and then find no ear
+			// but the fan around node 0 avoids the existing edges.
+			name:     "hexagon",
+			poly:     []Point{{X: 0, Y: 0}, {X: 2, Y: 0}, {X: 3, Y: 1}, {X: 2, Y: 2}, {X: 0, Y: 2}, {X: -1, Y: 1}},
+			existing: [][2]int{{2, 4}, {3, 5}},
+			ok:       true,
+		},
+		{
+			// The diagonals of all four ears of the U shape exist, so the
+			// quadrilaterals of its arms cannot be triangulated.
+			name: "u",
+			poly: []Point{{X: 0, Y: 0}, {X: 3, Y: 0}, {X: 3, Y: 3}, {X: 2, Y: 3},
+				{X: 2, Y: 1}, {X: 1, Y: 1}, {X: 1, Y: 3}, {X: 0, Y: 3}},
+			existing: [][2]int{{1, 3}, {2, 4}, {5, 7}, {6, 0}},
+			ok:       false,
+		},
+	} {
+		g := newPolygons(test.poly)
+		f := g.Face(0)
+		nodes := make([]Node, len(test.poly))
+		for i, h := range g.HalfedgesAround(f) {
+			nodes[i] = h.From()
+		}
+		// Connect the nodes by edges outside of the face.
+		for _, e := range test.existing {
+			x := addPointNode(g, g.NewNodeID(), Point{X: 10, Y: 10})
+			if err := g.AddFace(g.NewFaceID(), nodes[e[1]], nodes[e[0]], x); err != nil {
+				t.Fatal(err)
+			}
+		}
+		faces, err := g.TriangulateFace(f)
+		if !test.ok {
+			if err == nil {
+				t.Errorf("%s: expected error", test.name)
+			}
+			if len(g.HalfedgesAround(f)) != len(test.poly) {
+				t.Errorf("%s: face modified after failed triangulation", test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		var total float64
+		for _, tf := range faces {
+			a := faceArea(g, tf)
+			if a <= 0 {
+				t.Errorf("%s: triangle %d is not counterclockwise", test.name, tf.ID())
+			}
+			total += a
+		}
+		if want := area(test.poly); math.Abs(total-want) > 1e-12 {
+			t.Errorf("%s: triangles overlap: total area %v, want %v", test.name, total, want)
+		}
+	}
+}
+
+func TestTriangulateFaceRestore(t *testing.T) {
+	// Simulate a failure after two triangles of a hexagon have been added.
+	g := newPolygons([]Point{{X: 0}, {X: 2}, {X: 3, Y: 1}, {X: 2, Y: 2}, {Y: 2}, {X: -1, Y: 1}})
+	f := g.Face(0)
+	nextEdge, nextFace := g.nextEdgeID, g.nextFaceID
+	var nodes []Node
+	for _, h := range g.HalfedgesAround(f) {
+		nodes = append(nodes, h.From())
+	}
+	tris, _ := g.fan(nodes)
+	g.RemoveFace(f)
+	var added []Face
+	for i, tri := range tris[:2] {
+		id, tf := f.ID(), f
+		if i > 0 {
+			id, tf = g.NewFaceID(), nil
+		}
+		if err := g.addFace(id, tf, []graph.Node{nodes[tri[0]], nodes[tri[1]], nodes[tri[2]]}); err != nil {
+			t.Fatal(err)
+		}
+		added = append(added, g.faces[id])
+	}
+	// A diagonal of the failed triangle.
+	if _, err := g.addEdge(nodes[0], nodes[4]); err != nil {
+		t.Fatal(err)
+	}
+
+	g.untriangulate(f, nodes, tris, added)
+	if len(g.Faces()) != 1 || g.Face(0) != f || len(g.HalfedgesAround(f)) != 6 || g.Edges().Len() != 6 {
+		t.Error("dcel: face not restored")
+	}
+	if g.nextEdgeID != nextEdge || g.nextFaceID != nextFace {
+		t.Errorf("dcel: IDs not restored: next edge %d, want %d, next face %d, want %d",
+			g.nextEdgeID, nextEdge, g.nextFaceID, nextFace)
+	}
+	checkLinks(t, g)
+}
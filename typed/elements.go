@@ -0,0 +1,80 @@
+package typed
+
+// Node is a graph node that stores a value of type N.
+type Node[N, E, F any] struct {
+	// Value is the value stored in the node.
+	Value N
+
+	id int64
+	h  *Halfedge[N, E, F]
+}
+
+// ID returns the node identifier.
+func (u *Node[N, E, F]) ID() int64 { return u.id }
+
+// Halfedge returns the outgoing halfedge from the node. When the node is
+// isolated, the returned halfedge is nil. When the node is at a boundary, the
+// halfedge's Face is nil.
+func (u *Node[N, E, F]) Halfedge() *Halfedge[N, E, F] { return u.h }
+
+// Halfedge is an oriented edge. Halfedges are stored inline in their edges.
+type Halfedge[N, E, F any] struct {
+	from       *Node[N, E, F]
+	twin       *Halfedge[N, E, F]
+	next, prev *Halfedge[N, E, F]
+	edge       *Edge[N, E, F]
+	face       *Face[N, E, F]
+}
+
+// From returns the origin node.
+func (h *Halfedge[N, E, F]) From() *Node[N, E, F] { return h.from }
+
+// To returns the destination node.
+func (h *Halfedge[N, E, F]) To() *Node[N, E, F] { return h.twin.from }
+
+// Twin returns the twin halfedge in the same edge.
+func (h *Halfedge[N, E, F]) Twin() *Halfedge[N, E, F] { return h.twin }
+
+// Next returns the next halfedge around the adjacent face.
+func (h *Halfedge[N, E, F]) Next() *Halfedge[N, E, F] { return h.next }
+
+// Prev returns the previous halfedge around the adjacent face.
+func (h *Halfedge[N, E, F]) Prev() *Halfedge[N, E, F] { return h.prev }
+
+// Edge returns the undirected edge to which the halfedge belongs.
+func (h *Halfedge[N, E, F]) Edge() *Edge[N, E, F] { return h.edge }
+
+// Face returns the adjacent face or nil if the halfedge is at a boundary.
+func (h *Halfedge[N, E, F]) Face() *Face[N, E, F] { return h.face }
+
+// Edge is an undirected edge that stores a value of type E.
+type Edge[N, E, F any] struct {
+	// Value is the value stored in the edge.
+	Value E
+
+	id int64
+	h  [2]Halfedge[N, E, F]
+}
+
+// ID returns the edge identifier.
+func (e *Edge[N, E, F]) ID() int64 { return e.id }
+
+// Halfedges returns the two halfedges that form the edge.
+func (e *Edge[N, E, F]) Halfedges() (*Halfedge[N, E, F], *Halfedge[N, E, F]) {
+	return &e.h[0], &e.h[1]
+}
+
+// Face is a face that stores a value of type F.
+type Face[N, E, F any] struct {
+	// Value is the value stored in the face.
+	Value F
+
+	id int64
+	h  *Halfedge[N, E, F]
+}
+
+// ID returns the face identifier.
+func (f *Face[N, E, F]) ID() int64 { return f.id }
+
+// Halfedge returns an adjacent halfedge.
+func (f *Face[N, E, F]) Halfedge() *Halfedge[N, E, F] { return f.h }
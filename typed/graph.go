@@ -0,0 +1,593 @@
+// Package typed provides a doubly-connected edge list whose nodes, edges and
+// faces store values of type parameters instead of being allocated through an
+// Items factory. The elements are concrete types, so traversing the graph does
+// not go through interface method calls.
+package typed
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Graph implements the doubly-connected edge list data structure with nodes
+// storing values of type N, edges storing values of type E and faces storing
+// values of type F.
+type Graph[N, E, F any] struct {
+	nodes map[int64]*Node[N, E, F]
+	edges map[int64]*Edge[N, E, F]
+	faces map[int64]*Face[N, E, F]
+
+	nextNodeID int64
+	nextEdgeID int64
+	nextFaceID int64
+
+	freeNodes map[int64]struct{}
+	freeEdges map[int64]struct{}
+	freeFaces map[int64]struct{}
+}
+
+// New returns a new Graph.
+func New[N, E, F any]() *Graph[N, E, F] {
+	return &Graph[N, E, F]{
+		nodes: make(map[int64]*Node[N, E, F]),
+		edges: make(map[int64]*Edge[N, E, F]),
+		faces: make(map[int64]*Face[N, E, F]),
+
+		freeNodes: make(map[int64]struct{}),
+		freeEdges: make(map[int64]struct{}),
+		freeFaces: make(map[int64]struct{}),
+	}
+}
+
+// Node returns the node with the given id or nil if it does not exist within
+// the graph.
+func (g *Graph[N, E, F]) Node(id int64) *Node[N, E, F] { return g.nodes[id] }
+
+// Edge returns the edge with the given id or nil if it does not exist within
+// the graph.
+func (g *Graph[N, E, F]) Edge(id int64) *Edge[N, E, F] { return g.edges[id] }
+
+// Face returns the face with the given id or nil if it does not exist within
+// the graph.
+func (g *Graph[N, E, F]) Face(id int64) *Face[N, E, F] { return g.faces[id] }
+
+// Nodes returns all the nodes in the graph ordered by ID.
+func (g *Graph[N, E, F]) Nodes() []*Node[N, E, F] {
+	nodes := make([]*Node[N, E, F], 0, len(g.nodes))
+	for _, id := range sortedIDs(g.nodes) {
+		nodes = append(nodes, g.nodes[id])
+	}
+	return nodes
+}
+
+// Edges returns all the edges in the graph ordered by ID.
+func (g *Graph[N, E, F]) Edges() []*Edge[N, E, F] {
+	edges := make([]*Edge[N, E, F], 0, len(g.edges))
+	for _, id := range sortedIDs(g.edges) {
+		edges = append(edges, g.edges[id])
+	}
+	return edges
+}
+
+// Faces returns all the faces in the graph ordered by ID.
+func (g *Graph[N, E, F]) Faces() []*Face[N, E, F] {
+	faces := make([]*Face[N, E, F], 0, len(g.faces))
+	for _, id := range sortedIDs(g.faces) {
+		faces = append(faces, g.faces[id])
+	}
+	return faces
+}
+
+// From returns all neighbors of the node with the given id.
+func (g *Graph[N, E, F]) From(id int64) []*Node[N, E, F] {
+	var from []*Node[N, E, F]
+	for _, h := range g.HalfedgesFrom(id) {
+		from = append(from, h.To())
+	}
+	return from
+}
+
+// HasEdgeBetween returns whether an edge exists between nodes with IDs xid and
+// yid.
+func (g *Graph[N, E, F]) HasEdgeBetween(xid, yid int64) bool {
+	return g.Halfedge(xid, yid) != nil
+}
+
+// EdgeBetween returns the edge between nodes with IDs xid and yid or nil if
+// the nodes are not connected.
+func (g *Graph[N, E, F]) EdgeBetween(xid, yid int64) *Edge[N, E, F] {
+	h := g.Halfedge(xid, yid)
+	if h == nil {
+		return nil
+	}
+	return h.edge
+}
+
+// Halfedge returns the halfedge from the node with ID uid to the node with ID
+// vid, or nil if the nodes are not connected by an edge or at least one is
+// isolated.
+func (g *Graph[N, E, F]) Halfedge(uid, vid int64) *Halfedge[N, E, F] {
+	u := g.nodes[uid]
+	v := g.nodes[vid]
+	if u == nil || v == nil {
+		// One of the nodes does not belong to the graph.
+		return nil
+	}
+	if u.h == nil || v.h == nil {
+		// At least one of the nodes is isolated.
+		return nil
+	}
+	start := u.h // An outgoing halfedge from u.
+	for iter := start; ; {
+		if iter.twin.from == v {
+			return iter
+		}
+		iter = iter.twin.next
+		if iter == start {
+			break
+		}
+	}
+	// If we get here, the nodes are not connected.
+	return nil
+}
+
+// NewNodeID returns a new node id unique within the graph.
+func (g *Graph[N, E, F]) NewNodeID() int64 {
+	return newID(&g.nextNodeID, g.nodes, g.freeNodes, "node")
+}
+
+// NewFaceID returns a new face id unique within the graph.
+func (g *Graph[N, E, F]) NewFaceID() int64 {
+	return newID(&g.nextFaceID, g.faces, g.freeFaces, "face")
+}
+
+// AddNode adds a new, isolated node with the given id to the graph and returns
+// it. AddNode panics if a node with same id already exists in the graph.
+func (g *Graph[N, E, F]) AddNode(id int64) *Node[N, E, F] {
+	if _, exists := g.nodes[id]; exists {
+		panic(fmt.Sprintf("typed: node ID collision: %d", id))
+	}
+	u := &Node[N, E, F]{id: id}
+	g.nodes[id] = u
+	delete(g.freeNodes, id)
+	g.nextNodeID = nextID(g.nextNodeID, id)
+	return u
+}
+
+// RemoveNode removes the node with the given id from the graph as well as any
+// edges attached to it.
+func (g *Graph[N, E, F]) RemoveNode(id int64) {
+	u, exists := g.nodes[id]
+	if !exists {
+		// Nothing to do.
+		return
+	}
+
+	// Remove any attached edges.
+	for _, h := range g.HalfedgesFrom(id) {
+		g.RemoveEdge(id, h.To().id)
+	}
+
+	delete(g.nodes, id)
+	if g.nextNodeID != 0 && id == g.nextNodeID-1 {
+		g.nextNodeID--
+	}
+	g.freeNodes[id] = struct{}{}
+	u.h = nil
+}
+
+// addEdge adds a new edge between nodes with IDs xid and yid and returns its
+// halfedge from x to y. If the nodes are not in the graph, they are added.
+//
+// addEdge panics if xid == yid.
+func (g *Graph[N, E, F]) addEdge(xid, yid int64) (*Halfedge[N, E, F], error) {
+	if xid == yid {
+		panic(fmt.Sprintf("typed: trying to set a loop edge at node %d", xid))
+	}
+
+	h := g.Halfedge(xid, yid)
+	if h != nil {
+		// Edge between x and y already exists, so return the halfedge.
+		return h, nil
+	}
+
+	// Add any missing node.
+	u, ok := g.nodes[xid]
+	if !ok {
+		u = g.AddNode(xid)
+	}
+	v, ok := g.nodes[yid]
+	if !ok {
+		v = g.AddNode(yid)
+	}
+
+	// Allocate a new edge and attach it to the graph. The edge gets its ID
+	// only when it has been attached so that a failure does not consume an
+	// ID.
+	e := newEdge[N, E, F]()
+	h1, h2 := &e.h[0], &e.h[1]
+	if err := h1.attach(u); err != nil {
+		return nil, err
+	}
+	if err := h2.attach(v); err != nil {
+		h1.detach()
+		return nil, err
+	}
+
+	e.id = newID(&g.nextEdgeID, g.edges, g.freeEdges, "edge")
+	g.edges[e.id] = e
+	delete(g.freeEdges, e.id)
+	g.nextEdgeID = nextID(g.nextEdgeID, e.id)
+
+	return h1, nil
+}
+
+// newEdge allocates a new, properly initialized edge without an ID not
+// connected to any node.
+func newEdge[N, E, F any]() *Edge[N, E, F] {
+	e := &Edge[N, E, F]{}
+	h1, h2 := &e.h[0], &e.h[1]
+	h1.twin, h2.twin = h2, h1
+	h1.next, h2.next = h2, h1
+	h1.prev, h2.prev = h2, h1
+	h1.edge, h2.edge = e, e
+	return e
+}
+
+// attach connects h as an outgoing halfedge to u.
+func (h *Halfedge[N, E, F]) attach(u *Node[N, E, F]) error {
+	h.from = u
+	if u.h == nil {
+		// From node is isolated.
+		u.h = h
+		h.prev = h.twin
+		h.twin.next = h
+		return nil
+	}
+
+	// From node is not isolated, so we must update its neighboring halfedges.
+	// First find a free (i.e., without an adjacent face) halfedge from u.
+	out := u.h
+	for out.face != nil {
+		out = out.twin.next
+		if out == u.h {
+			return fmt.Errorf("typed: no free halfedge from node %d", u.id)
+		}
+	}
+
+	// Adjust the connections.
+	in := out.prev
+	in.next = h
+	h.prev = in
+	h.twin.next = out
+	out.prev = h.twin
+
+	return nil
+}
+
+// RemoveEdge removes the edge between nodes with IDs fid and tid and its
+// adjacent faces from g.
+func (g *Graph[N, E, F]) RemoveEdge(fid, tid int64) {
+	h := g.Halfedge(fid, tid)
+	if h == nil {
+		// Nothing to do.
+		return
+	}
+
+	// Remove any adjacent faces.
+	if h.face != nil {
+		g.RemoveFace(h.face)
+	}
+	if h.twin.face != nil {
+		g.RemoveFace(h.twin.face)
+	}
+
+	// Both halfedges must be detached before they are cleared because
+	// detaching one of them uses the connections of the other.
+	e := h.edge
+	h1, h2 := &e.h[0], &e.h[1]
+	h1.detach()
+	h2.detach()
+	e.h = [2]Halfedge[N, E, F]{}
+
+	delete(g.edges, e.id)
+	if g.nextEdgeID != 0 && e.id == g.nextEdgeID-1 {
+		g.nextEdgeID--
+	}
+	g.freeEdges[e.id] = struct{}{}
+}
+
+// detach disconnects h from its From node.
+func (h *Halfedge[N, E, F]) detach() {
+	if h.face != nil {
+		panic("typed: face not removed before detaching halfedge")
+	}
+
+	out := h.twin.next
+	in := h.prev
+	from := h.from
+	if from.h == h {
+		// h is the halfedge referenced by its from node.
+		if out == h {
+			// It is also the only halfedge adjacent to the from node, so it
+			// will become isolated.
+			from.h = nil
+		} else {
+			if out.face != nil {
+				panic("typed: outgoing halfedge is not free")
+			}
+			from.h = out
+		}
+	}
+	out.prev = in
+	in.next = out
+}
+
+// HasFace returns whether a face with the given id exists in the graph.
+func (g *Graph[N, E, F]) HasFace(id int64) bool {
+	_, exists := g.faces[id]
+	return exists
+}
+
+// AddFace adds a new face with given ID and with vertices given by the IDs of
+// nodes and returns it. Any missing node or edge between two consecutive nodes
+// will be added to the graph first.
+//
+// If the nodes are not pair-wise distinct, if two consecutive nodes are
+// already connected by a halfedge with an adjacent Face, or if the existing
+// graph topology does not permit adding the face, an error will be returned.
+//
+// AddFace panics if a face with the given id already exists in the graph or if
+// the length of nodes is less than 3.
+func (g *Graph[N, E, F]) AddFace(id int64, nodes ...int64) (*Face[N, E, F], error) {
+	if g.HasFace(id) {
+		panic(fmt.Sprintf("typed: face ID collision: %d", id))
+	}
+	if len(nodes) < 3 {
+		panic(fmt.Sprintf("typed: cannot add face %d with only %d nodes", id, len(nodes)))
+	}
+
+	// Check that the nodes are pair-wise distinct.
+	for i, x := range nodes {
+		for _, y := range nodes[i+1:] {
+			if x == y {
+				return nil, fmt.Errorf("typed: cannot add face %d, duplicit node %d", id, x)
+			}
+		}
+	}
+
+	// Collect (and add any missing) halfedges between consecutive nodes.
+	hedges := make([]*Halfedge[N, E, F], len(nodes))
+	for i, x := range nodes {
+		y := nodes[(i+1)%len(nodes)]
+		h, err := g.addEdge(x, y)
+		if err != nil {
+			return nil, err
+		}
+		if h.face != nil {
+			return nil, fmt.Errorf("typed: cannot add face %d, halfedge from %d to %d is not free", id, x, y)
+		}
+		hedges[i] = h
+	}
+
+	// Reconnect the halfedges so the Next and Prev point to consecutive
+	// neighbors.
+	for i, h1 := range hedges {
+		h2 := hedges[(i+1)%len(hedges)]
+		if err := reconnect(h1, h2); err != nil {
+			return nil, err
+		}
+	}
+
+	f := &Face[N, E, F]{id: id, h: hedges[0]}
+	for _, h := range hedges {
+		h.face = f
+	}
+
+	g.faces[id] = f
+	delete(g.freeFaces, id)
+	g.nextFaceID = nextID(g.nextFaceID, id)
+
+	return f, nil
+}
+
+// reconnect adjusts the halfedges around the shared node between in and out so
+// that in.next == out and out.prev == in.
+// It panics if in and out do not share a common node.
+func reconnect[N, E, F any](in, out *Halfedge[N, E, F]) error {
+	if in.twin.from != out.from {
+		panic("typed.reconnect: halfedges are not connected")
+	}
+
+	if in.next == out || out.prev == in {
+		if in.next != out || out.prev != in {
+			// This would be our bug.
+			panic(fmt.Sprintf("typed.reconnect: halfedges around node %d are inconsistently connected",
+				out.from.id))
+		}
+		// in and out are already adjacent.
+		return nil
+	}
+
+	// Find a free incoming halfedge adjacent to the common node between
+	// out.twin and in.
+	var b *Halfedge[N, E, F]
+	for iter := out.twin; ; {
+		if iter.face == nil {
+			b = iter
+			break
+		}
+		iter = iter.next.twin
+		if iter == in {
+			break
+		}
+	}
+	if b == nil {
+		return fmt.Errorf("typed: halfedge reconnection failed around node %d", out.from.id)
+	}
+
+	// Reconnect the halfedges.
+	inNext := in.next
+	outPrev := out.prev
+	bNext := b.next
+
+	in.next = out
+	out.prev = in
+
+	b.next = inNext
+	inNext.prev = b
+
+	outPrev.next = bNext
+	bNext.prev = outPrev
+
+	return nil
+}
+
+// RemoveFace disconnects f from g and sets its Halfedge to nil.
+func (g *Graph[N, E, F]) RemoveFace(f *Face[N, E, F]) {
+	if g.faces[f.id] != f {
+		// Nothing to do, the face does not belong to the graph.
+		return
+	}
+
+	// Disconnect the face from its adjacent halfedges.
+	for _, h := range g.HalfedgesAround(f) {
+		h.face = nil
+	}
+	f.h = nil
+
+	delete(g.faces, f.id)
+	if g.nextFaceID != 0 && f.id == g.nextFaceID-1 {
+		g.nextFaceID--
+	}
+	g.freeFaces[f.id] = struct{}{}
+}
+
+// HalfedgesFrom returns all halfedges whose From node has the given id.
+func (g *Graph[N, E, F]) HalfedgesFrom(id int64) []*Halfedge[N, E, F] {
+	u := g.nodes[id]
+	if u == nil || u.h == nil {
+		// The node does not belong to the graph or it is isolated.
+		return nil
+	}
+	var hedges []*Halfedge[N, E, F]
+	for iter := u.h; ; {
+		hedges = append(hedges, iter)
+		iter = iter.twin.next
+		if iter == u.h {
+			break
+		}
+	}
+	return hedges
+}
+
+// HalfedgesTo returns all halfedges whose Twin.From node has the given id.
+func (g *Graph[N, E, F]) HalfedgesTo(id int64) []*Halfedge[N, E, F] {
+	hedges := g.HalfedgesFrom(id)
+	for i, h := range hedges {
+		hedges[i] = h.twin
+	}
+	return hedges
+}
+
+// HalfedgesAround returns all halfedges adjacent to the given face.
+func (g *Graph[N, E, F]) HalfedgesAround(f *Face[N, E, F]) []*Halfedge[N, E, F] {
+	if g.faces[f.id] != f || f.h == nil {
+		return nil
+	}
+	return g.Loop(f.h)
+}
+
+// Loop returns the halfedges of the loop that h belongs to, starting with h and
+// following Next. For a halfedge with an adjacent face it returns the
+// halfedges around the face, for a boundary halfedge it returns the halfedges
+// of the boundary loop.
+func (g *Graph[N, E, F]) Loop(h *Halfedge[N, E, F]) []*Halfedge[N, E, F] {
+	if h == nil || h.from == nil || g.nodes[h.from.id] != h.from {
+		// The halfedge does not belong to the graph.
+		return nil
+	}
+	var hedges []*Halfedge[N, E, F]
+	for iter := h; ; {
+		hedges = append(hedges, iter)
+		iter = iter.next
+		if iter == h {
+			break
+		}
+	}
+	return hedges
+}
+
+// BoundaryLoops returns one halfedge from each boundary loop in the graph. A
+// boundary loop is a loop of halfedges without an adjacent face.
+func (g *Graph[N, E, F]) BoundaryLoops() []*Halfedge[N, E, F] {
+	var (
+		loops []*Halfedge[N, E, F]
+		seen  = make(map[*Halfedge[N, E, F]]struct{})
+	)
+	for _, e := range g.Edges() {
+		for i := range e.h {
+			h := &e.h[i]
+			if h.face != nil {
+				continue
+			}
+			if _, ok := seen[h]; ok {
+				continue
+			}
+			for _, lh := range g.Loop(h) {
+				seen[lh] = struct{}{}
+			}
+			loops = append(loops, h)
+		}
+	}
+	return loops
+}
+
+// newID returns an ID unused in elems. next is the counter of unused IDs and
+// free is the set of released IDs.
+func newID[T any](next *int64, elems map[int64]T, free map[int64]struct{}, kind string) int64 {
+	if *next != maxID {
+		id := *next
+		*next++
+		return id
+	}
+	// All IDs have already been used. See if at least one has been released.
+	for id := range free {
+		return id
+	}
+	if int64(len(elems)) == maxID {
+		panic("typed: graph too large")
+	}
+	// Resort to checking all positive integers to see if there is at least one
+	// unused.
+	for id := int64(0); id < maxID; id++ {
+		if _, exists := elems[id]; !exists {
+			return id
+		}
+	}
+	panic(fmt.Sprintf("typed: no free %s ID", kind))
+}
+
+// nextID returns the new value of a counter of unused IDs after id has been
+// taken. The counter holds the smallest ID larger than all IDs in use, or
+// maxID if all IDs up to maxID have been used and the free IDs will have to
+// be looked up.
+func nextID(next, id int64) int64 {
+	if id == maxID {
+		return maxID
+	}
+	return max(next, id+1)
+}
+
+// sortedIDs returns the keys of m in increasing order.
+func sortedIDs[T any](m map[int64]T) []int64 {
+	ids := make([]int64, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+const maxID int64 = math.MaxInt64
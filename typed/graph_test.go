@@ -0,0 +1,235 @@
+package typed
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/graph/simple"
+
+	"github.com/vladimir-ch/dcel"
+)
+
+// point is a node value used in tests.
+type point struct{ x, y float64 }
+
+func TestTwoTriangles(t *testing.T) {
+	g := New[point, float64, string]()
+
+	f0, err := g.AddFace(0, 0, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f1, err := g.AddFace(1, 2, 1, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f0.Value, f1.Value = "lower", "upper"
+	for i, p := range []point{{0, 0}, {1, 0}, {0, 1}, {1, 1}} {
+		g.Node(int64(i)).Value = p
+	}
+	g.EdgeBetween(1, 2).Value = 1.5
+
+	if len(g.HalfedgesAround(f0)) != 3 || len(g.HalfedgesAround(f1)) != 3 {
+		t.Error("typed: wrong number of halfedges")
+	}
+	for i := 0; i < 3; i++ {
+		h := g.Halfedge(int64(i), int64((i+1)%3))
+		if h == nil {
+			t.Fatal("typed: halfedge does not exist")
+		}
+		if h.Face() != f0 || h.Face().Value != "lower" {
+			t.Error("typed: wrong face of halfedge")
+		}
+	}
+	if len(g.Nodes()) != 4 || len(g.Edges()) != 5 || len(g.Faces()) != 2 {
+		t.Error("typed: wrong size of graph")
+	}
+	h := g.Halfedge(2, 1)
+	if h.Face() != f1 || h.Twin().Face() != f0 || h.Edge().Value != 1.5 || h.To().Value != (point{1, 0}) {
+		t.Error("typed: wrong connections of shared edge")
+	}
+
+	if id := g.NewNodeID(); g.Node(id) != nil || id != 4 {
+		t.Errorf("typed: wrong new node ID %d", id)
+	}
+	if id := g.NewFaceID(); g.Face(id) != nil || id != 2 {
+		t.Errorf("typed: wrong new face ID %d", id)
+	}
+	if _, err = g.AddFace(2, 0, 1, 3); err == nil {
+		t.Error("typed: expected error for a face over a used halfedge")
+	}
+}
+
+func TestBoundaryLoops(t *testing.T) {
+	g := New[struct{}, struct{}, struct{}]()
+
+	// Four squares with one of them removed.
+	for i, f := range [][]int64{{0, 1, 4, 3}, {1, 2, 5, 4}, {3, 4, 7, 6}, {4, 5, 8, 7}} {
+		if _, err := g.AddFace(int64(i), f...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	g.RemoveFace(g.Face(3))
+	// The edges of the removed square separate the outer boundary loop from
+	// the loop of the removed square.
+	loops := g.BoundaryLoops()
+	if len(loops) != 2 {
+		t.Fatalf("typed: wrong number of boundary loops: %d", len(loops))
+	}
+	if n := len(g.Loop(loops[0])) + len(g.Loop(loops[1])); n != 12 {
+		t.Errorf("typed: wrong number of halfedges in boundary loops: %d", n)
+	}
+	if len(g.From(4)) != 4 || len(g.HalfedgesTo(4)) != 4 {
+		t.Error("typed: wrong neighbors of the center node")
+	}
+}
+
+func TestRemove(t *testing.T) {
+	g := New[int, int, int]()
+
+	if _, err := g.AddFace(0, 0, 1, 2); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.AddFace(1, 2, 1, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	g.RemoveEdge(1, 2)
+	if g.HasEdgeBetween(1, 2) {
+		t.Error("typed: removed edge still exists")
+	}
+	if len(g.Faces()) != 0 {
+		t.Error("typed: faces adjacent to removed edge still exist")
+	}
+	if len(g.Edges()) != 4 {
+		t.Errorf("typed: wrong number of edges: %d", len(g.Edges()))
+	}
+	if len(g.Loop(g.Halfedge(0, 1))) != 4 {
+		t.Error("typed: wrong boundary loop after edge removal")
+	}
+
+	g.RemoveNode(0)
+	if g.Node(0) != nil {
+		t.Error("typed: removed node still exists")
+	}
+	if len(g.Edges()) != 2 {
+		t.Errorf("typed: wrong number of edges: %d", len(g.Edges()))
+	}
+	if len(g.From(1)) != 1 || len(g.From(2)) != 1 {
+		t.Error("typed: wrong neighbors after node removal")
+	}
+
+	f, err := g.AddFace(g.NewFaceID(), 1, 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Face(f.ID()) != f || len(g.Edges()) != 3 {
+		t.Error("typed: wrong graph after adding a face")
+	}
+}
+
+func TestParity(t *testing.T) {
+	// Apply the same random changes to a typed graph and to a dcel.Graph and
+	// check that they agree on the elements, their IDs and the errors.
+	g := New[int, int, int]()
+	c := dcel.New(nil)
+	// Start with a closed fan around node 0 and a face that cannot be added
+	// because node 0 has no free halfedge.
+	fixed := [][]int64{{0, 1, 2}, {0, 2, 3}, {0, 3, 4}, {0, 4, 1}, {0, 5, 6}, {5, 6, 7}}
+	rnd := rand.New(rand.NewPCG(1, 1))
+	for step := 0; step < 2000; step++ {
+		op := rnd.IntN(10)
+		if step < len(fixed) {
+			op = 0
+		}
+		switch {
+		case op < 6:
+			ids := make([]int64, 3+rnd.IntN(2))
+			for i := range ids {
+				ids[i] = rnd.Int64N(12)
+			}
+			if step < len(fixed) {
+				ids = fixed[step]
+			}
+			fid, cfid := g.NewFaceID(), c.NewFaceID()
+			if fid != cfid {
+				t.Fatalf("step %d: new face ID %d, want %d", step, fid, cfid)
+			}
+			nodes := make([]graph.Node, len(ids))
+			for i, id := range ids {
+				nodes[i] = simple.Node(id)
+			}
+			_, err := g.AddFace(fid, ids...)
+			cerr := c.AddFace(cfid, nodes...)
+			if (err == nil) != (cerr == nil) {
+				t.Fatalf("step %d: adding face %v: got error %v, want %v", step, ids, err, cerr)
+			}
+		case op < 8:
+			if faces := g.Faces(); len(faces) > 0 {
+				f := faces[rnd.IntN(len(faces))]
+				c.RemoveFace(c.Face(f.ID()))
+				g.RemoveFace(f)
+			}
+		case op < 9:
+			u, v := rnd.Int64N(12), rnd.Int64N(12)
+			c.RemoveEdge(u, v)
+			g.RemoveEdge(u, v)
+		default:
+			id := rnd.Int64N(12)
+			c.RemoveNode(id)
+			g.RemoveNode(id)
+		}
+		checkParity(t, step, g, c)
+	}
+	if id, cid := g.NewNodeID(), c.NewNodeID(); id != cid {
+		t.Errorf("typed: new node ID %d, want %d", id, cid)
+	}
+}
+
+// checkParity checks that g and c have the same nodes, edges and faces.
+func checkParity(t *testing.T, step int, g *Graph[int, int, int], c *dcel.Graph) {
+	t.Helper()
+	nodes := g.Nodes()
+	if len(nodes) != c.Nodes().Len() {
+		t.Fatalf("step %d: %d nodes, want %d", step, len(nodes), c.Nodes().Len())
+	}
+	for _, u := range nodes {
+		if c.Node(u.ID()) == nil || len(g.From(u.ID())) != c.From(u.ID()).Len() {
+			t.Fatalf("step %d: node %d differs", step, u.ID())
+		}
+	}
+	edges := g.Edges()
+	if len(edges) != c.Edges().Len() {
+		t.Fatalf("step %d: %d edges, want %d", step, len(edges), c.Edges().Len())
+	}
+	for _, e := range edges {
+		h, _ := e.Halfedges()
+		ce := c.EdgeBetween(h.From().ID(), h.To().ID())
+		if ce == nil || ce.(dcel.Edge).ID() != e.ID() {
+			t.Fatalf("step %d: edge %d differs", step, e.ID())
+		}
+	}
+	faces := g.Faces()
+	if len(faces) != len(c.Faces()) {
+		t.Fatalf("step %d: %d faces, want %d", step, len(faces), len(c.Faces()))
+	}
+	for _, f := range faces {
+		cf := c.Face(f.ID())
+		if cf == nil {
+			t.Fatalf("step %d: face %d missing", step, f.ID())
+		}
+		hedges, chedges := g.HalfedgesAround(f), c.HalfedgesAround(cf)
+		if len(hedges) != len(chedges) {
+			t.Fatalf("step %d: face %d differs", step, f.ID())
+		}
+		for i, h := range hedges {
+			if h.From().ID() != chedges[i].From().ID() {
+				t.Fatalf("step %d: face %d differs", step, f.ID())
+			}
+		}
+	}
+	if len(g.BoundaryLoops()) != len(c.BoundaryLoops()) {
+		t.Fatalf("step %d: %d boundary loops, want %d", step, len(g.BoundaryLoops()), len(c.BoundaryLoops()))
+	}
+}
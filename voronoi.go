@@ -0,0 +1,439 @@
+package dcel
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"gonum.org/v1/gonum/graph"
+)
+
+// Voronoi returns the Voronoi diagram of sites in the XY plane clipped to box.
+// Each Voronoi cell that intersects the box is a counterclockwise face of the
+// returned graph and the face with ID i is the cell of sites[i]. Cells that do
+// not intersect the box have no face. The nodes of the graph carry the
+// positions of the vertices of the cells which include the corners of the box
+// and the points where the Voronoi edges cross the boundary of the box.
+//
+// If items is nil, PointBase will be used. Voronoi panics if items does not
+// allocate nodes that implement PointNode.
+//
+// If there are no sites, if two sites are equal or if box is empty, an error is
+// returned.
+func Voronoi(items Items, sites []Point, box Box) (*Graph, error) {
+	if items == nil {
+		items = PointBase{}
+	}
+	if len(sites) == 0 {
+		return nil, errors.New("dcel: no Voronoi sites")
+	}
+	if box.Empty() {
+		return nil, errors.New("dcel: empty bounding box")
+	}
+
+	var cells [][]voronoiHalfedge
+	if collinear(sites) {
+		var err error
+		cells, err = collinearCells(sites, box)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		tri, err := NewDelaunay(nil, sites)
+		if err != nil {
+			return nil, err
+		}
+		cells = delaunayCells(tri, box)
+	}
+
+	g := New(items)
+	nodes := make(map[Point]Node)
+	node := func(p Point) Node {
+		u, ok := nodes[p]
+		if !ok {
+			u = addPointNode(g, g.NewNodeID(), p)
+			nodes[p] = u
+		}
+		return u
+	}
+	addCell := func(id int64, pts []Point) error {
+		cell := make([]graph.Node, len(pts))
+		for i, p := range pts {
+			cell[i] = node(p)
+		}
+		if err := g.AddFace(id, cell...); err != nil {
+			return fmt.Errorf("dcel: cannot build Voronoi cell of site %d: %v", id, err)
+		}
+		return nil
+	}
+
+	var clipped bool
+	for i, cell := range cells {
+		pts := cellPoints(cell, box)
+		if len(pts) > 0 {
+			clipped = true
+		}
+		if len(pts) < 3 || area(pts) <= 0 {
+			// The cell touches the box only at its boundary.
+			continue
+		}
+		if err := addCell(int64(i), pts); err != nil {
+			return nil, err
+		}
+	}
+	if !clipped {
+		// No Voronoi edge intersects the box, so the box lies in the cell of
+		// the site nearest to its center.
+		center := box.Min.Add(box.Max).Scale(0.5)
+		var nearest int
+		for i, s := range sites {
+			if dist2D(s, center) < dist2D(sites[nearest], center) {
+				nearest = i
+			}
+		}
+		if err := addCell(int64(nearest), boxCorners(box)); err != nil {
+			return nil, err
+		}
+	}
+	return g, nil
+}
+
+// voronoiEdge is a Voronoi edge between two sites, clipped to a bounding box.
+// The edge lies on the line m + τ*d where m is the midpoint between the sites
+// and d is perpendicular to the segment between them.
+type voronoiEdge struct {
+	// ok is whether the edge intersects the box.
+	ok bool
+	// p0 and p1 are the ends of the clipped edge.
+	p0, p1 Point
+}
+
+// voronoiHalfedge is a Voronoi edge directed so that the cell of its site is
+// on the left.
+type voronoiHalfedge struct {
+	e   *voronoiEdge
+	rev bool
+}
+
+// clipVoronoiEdge returns the edge between sites s and t that is the part of
+// the line m + τ*d with τ in [a, b] that lies in box. The ends of the edge are
+// a Voronoi vertex pa if a is finite and pb if b is finite. pa and pb are used
+// as the ends of the clipped edge if they lie in the box.
+func clipVoronoiEdge(s, t Point, a, b float64, pa, pb Point, box Box) *voronoiEdge {
+	m, d := voronoiLine(s, t)
+	aIn := !math.IsInf(a, 0) && box.Contains(pa)
+	bIn := !math.IsInf(b, 0) && box.Contains(pb)
+	if aIn && bIn {
+		return &voronoiEdge{ok: true, p0: pa, p1: pb}
+	}
+	lo, hi := clipLine(m, d, box)
+	t0, t1 := math.Max(a, lo), math.Min(b, hi)
+	if aIn {
+		t0 = a
+	}
+	if bIn {
+		t1 = b
+	}
+	if !(t0 < t1) {
+		return &voronoiEdge{}
+	}
+	e := voronoiEdge{
+		ok: true,
+		p0: snap(m.Add(d.Scale(t0)), box),
+		p1: snap(m.Add(d.Scale(t1)), box),
+	}
+	if aIn {
+		e.p0 = pa
+	}
+	if bIn {
+		e.p1 = pb
+	}
+	return &e
+}
+
+// voronoiLine returns the bisector of the sites s and t as the line m + τ*d
+// with s on its left.
+func voronoiLine(s, t Point) (m, d Point) {
+	m = Point{X: (s.X + t.X) / 2, Y: (s.Y + t.Y) / 2}
+	d = Point{X: s.Y - t.Y, Y: t.X - s.X}
+	return m, d
+}
+
+// delaunayCells returns the Voronoi edges around each site of the Delaunay
+// triangulation tri in counterclockwise order.
+func delaunayCells(tri *Triangulation, box Box) [][]voronoiHalfedge {
+	g := tri.Graph()
+
+	// Triangles with a common circumcircle share their Voronoi vertex.
+	// Group them with a union-find structure.
+	parent := make(map[int64]int64)
+	var find func(id int64) int64
+	find = func(id int64) int64 {
+		p, ok := parent[id]
+		if !ok || p == id {
+			return id
+		}
+		r := find(p)
+		parent[id] = r
+		return r
+	}
+	for _, id := range g.edgeIDs() {
+		h, t := g.edges[id].Halfedges()
+		if h.Face() == nil || t.Face() == nil {
+			continue
+		}
+		a, b, c := point(h.From()), point(h.Next().From()), point(h.Prev().From())
+		if inCircle(a, b, c, point(t.Prev().From())) == 0 {
+			parent[find(h.Face().ID())] = find(t.Face().ID())
+		}
+	}
+	vertices := make(map[int64]Point)
+	vertex := func(f Face) Point {
+		id := find(f.ID())
+		p, ok := vertices[id]
+		if !ok {
+			h := g.faces[id].Halfedge()
+			p = circumcenter(point(h.From()), point(h.Next().From()), point(h.Prev().From()))
+			vertices[id] = p
+		}
+		return p
+	}
+
+	edges := make(map[int64]*voronoiEdge)
+	for _, id := range g.edgeIDs() {
+		h, t := g.edges[id].Halfedges()
+		if h.Face() != nil && t.Face() != nil && find(h.Face().ID()) == find(t.Face().ID()) {
+			// The Voronoi edge has zero length.
+			continue
+		}
+		s, u := point(h.From()), point(t.From())
+		m, d := voronoiLine(s, u)
+		var pa, pb Point
+		a, b := math.Inf(-1), math.Inf(1)
+		if t.Face() != nil {
+			pa = vertex(t.Face())
+			a = pa.Sub(m).Dot(d) / d.Dot(d)
+		}
+		if h.Face() != nil {
+			pb = vertex(h.Face())
+			b = pb.Sub(m).Dot(d) / d.Dot(d)
+		}
+		edges[id] = clipVoronoiEdge(s, u, a, b, pa, pb, box)
+	}
+
+	cells := make([][]voronoiHalfedge, g.Nodes().Len())
+	for i := range cells {
+		start := g.nodes[int64(i)].Halfedge()
+		for h := start; ; {
+			if e, ok := edges[h.Edge().ID()]; ok {
+				first, _ := h.Edge().Halfedges()
+				cells[i] = append(cells[i], voronoiHalfedge{e: e, rev: h != first})
+			}
+			h = h.Prev().Twin()
+			if h == start {
+				break
+			}
+		}
+	}
+	return cells
+}
+
+// collinearCells returns the Voronoi edges around each of the collinear sites.
+// The Voronoi cells are strips between parallel lines.
+func collinearCells(sites []Point, box Box) ([][]voronoiHalfedge, error) {
+	order := make([]int, len(sites))
+	for i := range order {
+		order[i] = i
+	}
+	var dir Point
+	for _, s := range sites {
+		if !samePosition(s, sites[0]) {
+			dir = Point{X: s.X - sites[0].X, Y: s.Y - sites[0].Y}
+			break
+		}
+	}
+	sort.Slice(order, func(i, j int) bool {
+		return sites[order[i]].Sub(sites[0]).Dot(dir) < sites[order[j]].Sub(sites[0]).Dot(dir)
+	})
+
+	cells := make([][]voronoiHalfedge, len(sites))
+	for k := 1; k < len(order); k++ {
+		i, j := order[k-1], order[k]
+		if samePosition(sites[i], sites[j]) {
+			return nil, fmt.Errorf("dcel: site %d equal to site %d", i, j)
+		}
+		e := clipVoronoiEdge(sites[i], sites[j], math.Inf(-1), math.Inf(1), Point{}, Point{}, box)
+		cells[i] = append(cells[i], voronoiHalfedge{e: e})
+		cells[j] = append(cells[j], voronoiHalfedge{e: e, rev: true})
+	}
+	return cells, nil
+}
+
+// collinear returns whether all points lie on a single line in the XY plane.
+func collinear(points []Point) bool {
+	for i := 1; i < len(points); i++ {
+		if samePosition(points[i], points[0]) {
+			continue
+		}
+		for _, p := range points[i+1:] {
+			if orient(points[0], points[i], p) != 0 {
+				return false
+			}
+		}
+		return true
+	}
+	return true
+}
+
+// cellPoints returns the vertices of the Voronoi cell with the given edges
+// clipped to box in counterclockwise order.
+func cellPoints(cell []voronoiHalfedge, box Box) []Point {
+	var pts []Point
+	for _, h := range cell {
+		if !h.e.ok {
+			continue
+		}
+		p0, p1 := h.e.p0, h.e.p1
+		if h.rev {
+			p0, p1 = p1, p0
+		}
+		if len(pts) > 0 && pts[len(pts)-1] != p0 {
+			pts = append(pts, boxCornersBetween(pts[len(pts)-1], p0, box)...)
+		}
+		pts = append(pts, p0, p1)
+	}
+	if len(pts) > 0 && pts[len(pts)-1] != pts[0] {
+		pts = append(pts, boxCornersBetween(pts[len(pts)-1], pts[0], box)...)
+	}
+
+	// Remove consecutive duplicates.
+	var vertices []Point
+	for i, p := range pts {
+		if p != pts[(i+1)%len(pts)] {
+			vertices = append(vertices, p)
+		}
+	}
+	return vertices
+}
+
+// boxCorners returns the corners of box in counterclockwise order.
+func boxCorners(box Box) []Point {
+	return []Point{
+		{X: box.Min.X, Y: box.Min.Y},
+		{X: box.Max.X, Y: box.Min.Y},
+		{X: box.Max.X, Y: box.Max.Y},
+		{X: box.Min.X, Y: box.Max.Y},
+	}
+}
+
+// boxCornersBetween returns the corners of box that lie strictly between the
+// points p and q on the boundary of the box in counterclockwise direction.
+func boxCornersBetween(p, q Point, box Box) []Point {
+	w, h := box.Max.X-box.Min.X, box.Max.Y-box.Min.Y
+	perimeter := 2 * (w + h)
+	sp, sq := boxParam(p, box), boxParam(q, box)
+	if sq <= sp {
+		sq += perimeter
+	}
+	var corners []Point
+	for _, s := range []float64{0, perimeter} {
+		for i, c := range boxCorners(box) {
+			if pc := s + [4]float64{0, w, w + h, 2*w + h}[i]; sp < pc && pc < sq {
+				corners = append(corners, c)
+			}
+		}
+	}
+	return corners
+}
+
+// boxParam returns the distance of p from the Min corner of box along the
+// boundary of the box in counterclockwise direction. p is projected on the
+// nearest side of the box.
+func boxParam(p Point, box Box) float64 {
+	w, h := box.Max.X-box.Min.X, box.Max.Y-box.Min.Y
+	bottom, right := p.Y-box.Min.Y, box.Max.X-p.X
+	top, left := box.Max.Y-p.Y, p.X-box.Min.X
+	switch math.Min(math.Min(bottom, right), math.Min(top, left)) {
+	case bottom:
+		return p.X - box.Min.X
+	case right:
+		return w + p.Y - box.Min.Y
+	case top:
+		return w + h + box.Max.X - p.X
+	default:
+		return 2*w + h + box.Max.Y - p.Y
+	}
+}
+
+// snap moves p, which lies approximately on the boundary of box, exactly onto
+// the nearest side of the box.
+func snap(p Point, box Box) Point {
+	p.X = math.Min(math.Max(p.X, box.Min.X), box.Max.X)
+	p.Y = math.Min(math.Max(p.Y, box.Min.Y), box.Max.Y)
+	bottom, right := p.Y-box.Min.Y, box.Max.X-p.X
+	top, left := box.Max.Y-p.Y, p.X-box.Min.X
+	switch math.Min(math.Min(bottom, right), math.Min(top, left)) {
+	case bottom:
+		p.Y = box.Min.Y
+	case right:
+		p.X = box.Max.X
+	case top:
+		p.Y = box.Max.Y
+	default:
+		p.X = box.Min.X
+	}
+	return p
+}
+
+// clipLine returns the interval of τ for which the point m + τ*d lies in box
+// using the Liang-Barsky algorithm. If the line does not intersect the box, lo
+// is greater than hi.
+func clipLine(m, d Point, box Box) (lo, hi float64) {
+	lo, hi = math.Inf(-1), math.Inf(1)
+	for _, c := range [][4]float64{
+		{m.X, d.X, box.Min.X, box.Max.X},
+		{m.Y, d.Y, box.Min.Y, box.Max.Y},
+	} {
+		x, dx, min, max := c[0], c[1], c[2], c[3]
+		if dx == 0 {
+			if x < min || max < x {
+				return 1, 0
+			}
+			continue
+		}
+		t0, t1 := (min-x)/dx, (max-x)/dx
+		if t0 > t1 {
+			t0, t1 = t1, t0
+		}
+		lo, hi = math.Max(lo, t0), math.Min(hi, t1)
+	}
+	return lo, hi
+}
+
+// circumcenter returns the center of the circle that passes through the
+// points a, b and c in the XY plane.
+func circumcenter(a, b, c Point) Point {
+	bx, by := b.X-a.X, b.Y-a.Y
+	cx, cy := c.X-a.X, c.Y-a.Y
+	d := 2 * (bx*cy - by*cx)
+	b2, c2 := bx*bx+by*by, cx*cx+cy*cy
+	return Point{
+		X: a.X + (cy*b2-by*c2)/d,
+		Y: a.Y + (bx*c2-cx*b2)/d,
+	}
+}
+
+// area returns the signed area of the polygon with vertices pts in the XY
+// plane. The area is positive if the vertices are in counterclockwise order.
+func area(pts []Point) float64 {
+	var a float64
+	for i, p := range pts {
+		q := pts[(i+1)%len(pts)]
+		a += p.X*q.Y - q.X*p.Y
+	}
+	return a / 2
+}
+
+// dist2D returns the Euclidean distance between p and q in the XY plane.
+func dist2D(p, q Point) float64 { return math.Hypot(p.X-q.X, p.Y-q.Y) }
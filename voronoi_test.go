@@ -0,0 +1,131 @@
+package dcel
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+)
+
+// checkVoronoi checks that the faces of g form a subdivision of box and that
+// the vertices of each face are not closer to another site than to the site of
+// the face.
+func checkVoronoi(t *testing.T, g *Graph, sites []Point, box Box) {
+	t.Helper()
+	var total float64
+	for _, f := range g.Faces() {
+		hedges := g.HalfedgesAround(f)
+		var area float64
+		for _, h := range hedges {
+			p, q := point(h.From()), point(h.Twin().From())
+			area += (p.X*q.Y - q.X*p.Y) / 2
+		}
+		if area <= 0 {
+			t.Errorf("dcel: face %d is not counterclockwise", f.ID())
+		}
+		total += area
+
+		s := sites[f.ID()]
+		for _, h := range hedges {
+			p := point(h.From())
+			if !box.Contains(p) {
+				t.Errorf("dcel: node %d outside of box", h.From().ID())
+			}
+			for _, q := range sites {
+				if dist2D(p, s) > dist2D(p, q)+1e-9 {
+					t.Errorf("dcel: node %d of cell %d closer to another site", h.From().ID(), f.ID())
+					break
+				}
+			}
+		}
+	}
+	want := (box.Max.X - box.Min.X) * (box.Max.Y - box.Min.Y)
+	if math.Abs(total-want) > 1e-9*want {
+		t.Errorf("dcel: wrong total area of cells: got %v, want %v", total, want)
+	}
+	topo := g.Topology()
+	if len(topo.Components) != 1 || topo.EulerCharacteristic != 1 || !topo.Components[0].Manifold {
+		t.Errorf("dcel: cells do not form a disk: %+v", topo)
+	}
+}
+
+func TestVoronoiRandom(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(1, 3))
+	box := Box{Min: Point{X: 0.2, Y: 0.1}, Max: Point{X: 0.9, Y: 0.8}}
+	for _, n := range []int{1, 2, 3, 10, 100, 1000} {
+		sites := make([]Point, n)
+		for i := range sites {
+			sites[i] = Point{X: rnd.Float64(), Y: rnd.Float64()}
+		}
+		g, err := Voronoi(nil, sites, box)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkVoronoi(t, g, sites, box)
+	}
+}
+
+func TestVoronoiGrid(t *testing.T) {
+	// The Delaunay triangulation of the sites has cocircular triangles.
+	var sites []Point
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			sites = append(sites, Point{X: float64(i) + 0.5, Y: float64(j) + 0.5})
+		}
+	}
+	box := Box{Max: Point{X: 4, Y: 4}}
+	g, err := Voronoi(nil, sites, box)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkVoronoi(t, g, sites, box)
+	if len(g.Faces()) != 16 || g.Nodes().Len() != 25 {
+		t.Errorf("dcel: wrong grid Voronoi diagram: %d faces, %d nodes", len(g.Faces()), g.Nodes().Len())
+	}
+	for _, f := range g.Faces() {
+		if n := len(g.HalfedgesAround(f)); n != 4 {
+			t.Errorf("dcel: cell %d has %d nodes", f.ID(), n)
+		}
+	}
+
+	// Only part of the cells intersect a smaller box.
+	box = Box{Min: Point{X: 0.5, Y: 0.5}, Max: Point{X: 1.5, Y: 2.5}}
+	g, err = Voronoi(nil, sites, box)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkVoronoi(t, g, sites, box)
+	if len(g.Faces()) != 6 {
+		t.Errorf("dcel: wrong number of cells: %d", len(g.Faces()))
+	}
+
+	// The box lies inside a single cell.
+	box = Box{Min: Point{X: 2.1, Y: 1.1}, Max: Point{X: 2.9, Y: 1.9}}
+	g, err = Voronoi(nil, sites, box)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkVoronoi(t, g, sites, box)
+	if len(g.Faces()) != 1 || g.Face(9) == nil {
+		t.Error("dcel: box not in cell 9")
+	}
+}
+
+func TestVoronoiCollinear(t *testing.T) {
+	sites := []Point{{X: 3, Y: 3}, {X: 1, Y: 1}, {X: 2, Y: 2}, {X: 0, Y: 0}}
+	box := Box{Min: Point{X: -1, Y: -1}, Max: Point{X: 4, Y: 4}}
+	g, err := Voronoi(nil, sites, box)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkVoronoi(t, g, sites, box)
+	if len(g.Faces()) != 4 {
+		t.Errorf("dcel: wrong number of cells: %d", len(g.Faces()))
+	}
+
+	if _, err := Voronoi(nil, []Point{{X: 1}, {X: 1}}, box); err == nil {
+		t.Error("dcel: expected error for equal sites")
+	}
+	if _, err := Voronoi(nil, sites, Box{}); err == nil {
+		t.Error("dcel: expected error for empty box")
+	}
+}
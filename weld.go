@@ -0,0 +1,443 @@
+package dcel
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// WeldNodes merges the nodes of the graph that are at most tolerance apart and
+// stitches the pairs of edges that then join the same nodes. The bound is
+// inclusive, so a zero tolerance merges the nodes at equal positions. Nodes
+// are merged transitively into the node with the smallest ID, which keeps its
+// position. Of each pair of stitched edges, the edge with the smaller ID is
+// kept and both faces become adjacent to it. The halfedges around the merged
+// nodes are relinked in place, so faces keep their IDs and property values.
+// Observers are notified of the removed nodes and edges and of the changed
+// links, see Observer.
+//
+// The returned map holds, for each merged node, the ID of the node it was
+// merged into.
+//
+// All nodes must carry a position. Only isolated nodes and nodes on a
+// boundary can be merged. An error is returned and the graph is not modified
+// if merged nodes are joined by an edge or belong to the same face, if edges
+// that would join the same nodes cannot be stitched because there are more
+// than two of them or because they do not have exactly one adjacent face each
+// on opposite sides, or if a closed fan of faces would touch other faces at a
+// merged node.
+//
+// WeldNodes panics if tolerance is negative.
+func (g *Graph) WeldNodes(tolerance float64) (map[int64]int64, error) {
+	if tolerance < 0 {
+		panic("dcel: negative weld tolerance")
+	}
+	ids := g.nodeIDs()
+	pts := make(map[int64]Point, len(ids))
+	for _, id := range ids {
+		p, ok := position(g.nodes[id])
+		if !ok {
+			return nil, errors.New("dcel: welding requires nodes with a position")
+		}
+		pts[id] = p
+	}
+
+	// Cluster the nodes using a grid of cells of size tolerance. Nodes are
+	// processed in increasing ID order so the first node of a cluster is its
+	// representative.
+	size := tolerance
+	if size == 0 {
+		size = 1
+	}
+	cellOf := func(p Point) [3]int64 {
+		return [3]int64{
+			int64(math.Floor(p.X / size)),
+			int64(math.Floor(p.Y / size)),
+			int64(math.Floor(p.Z / size)),
+		}
+	}
+	parent := make(map[int64]int64)
+	var root func(id int64) int64
+	root = func(id int64) int64 {
+		if p := parent[id]; p != id {
+			parent[id] = root(p)
+		}
+		return parent[id]
+	}
+	cells := make(map[[3]int64][]int64)
+	for _, id := range ids {
+		parent[id] = id
+		p := pts[id]
+		c := cellOf(p)
+		for dx := int64(-1); dx <= 1; dx++ {
+			for dy := int64(-1); dy <= 1; dy++ {
+				for dz := int64(-1); dz <= 1; dz++ {
+					for _, other := range cells[[3]int64{c[0] + dx, c[1] + dy, c[2] + dz}] {
+						if dist(p, pts[other]) > tolerance {
+							continue
+						}
+						a, b := root(id), root(other)
+						if a > b {
+							a, b = b, a
+						}
+						parent[b] = a
+					}
+				}
+			}
+		}
+		cells[c] = append(cells[c], id)
+	}
+	merged := make(map[int64]int64)
+	for _, id := range ids {
+		if r := root(id); r != id {
+			merged[id] = r
+		}
+	}
+	if len(merged) == 0 {
+		return merged, nil
+	}
+	p, err := g.planWeld(merged)
+	if err != nil {
+		return nil, err
+	}
+	p.apply()
+	return merged, nil
+}
+
+// planWeld plans the merging of each node with an ID in merged into the node
+// with the mapped ID and the stitching of the pairs of edges that then join the
+// same nodes. The mapped nodes must not be merged themselves. See WeldNodes for
+// details.
+//
+// Only the elements around the merged nodes are visited. The graph is not
+// modified, the changes are made when the returned plan is applied.
+func (g *Graph) planWeld(merged map[int64]int64) (*weldPlan, error) {
+	to := func(id int64) int64 {
+		if r, ok := merged[id]; ok {
+			return r
+		}
+		return id
+	}
+	ids := make([]int64, 0, len(merged))
+	for id := range merged {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	// Check that welding is possible before modifying the graph. Edges that
+	// join the same nodes after welding and faces whose nodes are merged
+	// have a merged node.
+	var (
+		edges    []Edge
+		faces    []Face
+		seenEdge = make(map[Edge]bool)
+		seenFace = make(map[Face]bool)
+	)
+	for _, id := range ids {
+		for _, id := range [2]int64{merged[id], id} {
+			if u := g.nodes[id]; u.Halfedge() != nil && freeHalfedge(u) == nil {
+				return nil, fmt.Errorf("dcel: cannot weld node %d, it is not on a boundary", id)
+			}
+			for _, h := range g.HalfedgesFrom(id) {
+				if e := h.Edge(); !seenEdge[e] {
+					seenEdge[e] = true
+					edges = append(edges, e)
+				}
+				if f := h.Face(); f != nil && !seenFace[f] {
+					seenFace[f] = true
+					faces = append(faces, f)
+				}
+			}
+		}
+	}
+	sort.Slice(edges, func(i, j int) bool { return edges[i].ID() < edges[j].ID() })
+	sort.Slice(faces, func(i, j int) bool { return faces[i].ID() < faces[j].ID() })
+	pairs := make(map[[2]int64][]Edge)
+	for _, e := range edges {
+		u, v := to(e.From().ID()), to(e.To().ID())
+		if u == v {
+			return nil, fmt.Errorf("dcel: cannot weld nodes %d and %d joined by an edge", e.From().ID(), e.To().ID())
+		}
+		key := edgeKey(u, v)
+		pairs[key] = append(pairs[key], e)
+	}
+	for _, f := range faces {
+		seen := make(map[int64]bool)
+		for _, h := range g.HalfedgesAround(f) {
+			u := to(h.From().ID())
+			if seen[u] {
+				return nil, fmt.Errorf("dcel: cannot weld nodes of face %d", f.ID())
+			}
+			seen[u] = true
+		}
+	}
+	keys := make([][2]int64, 0, len(pairs))
+	for key := range pairs {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i][0] < keys[j][0] || keys[i][0] == keys[j][0] && keys[i][1] < keys[j][1]
+	})
+
+	p := weldPlan{
+		g:    g,
+		to:   to,
+		ids:  ids,
+		next: make(map[Halfedge]Halfedge),
+		prev: make(map[Halfedge]Halfedge),
+		face: make(map[Halfedge]Face),
+
+		removed: make(map[Halfedge]bool),
+	}
+	for _, key := range keys {
+		pair := pairs[key]
+		if len(pair) == 1 {
+			continue
+		}
+		e1, e2 := pair[0], pair[1]
+		if len(pair) > 2 {
+			return nil, fmt.Errorf("dcel: cannot stitch %d edges between nodes %d and %d", len(pair), key[0], key[1])
+		}
+		h1, h2 := faceHalfedge(e1), faceHalfedge(e2)
+		if h1 == nil || h2 == nil || to(h1.From().ID()) == to(h2.From().ID()) {
+			return nil, fmt.Errorf("dcel: cannot stitch edges %d and %d", e1.ID(), e2.ID())
+		}
+		p.stitch(h1.Twin(), h2)
+	}
+	if err := p.join(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// weldPlan records the changes of the links between halfedges that weld nodes
+// and stitch edges without modifying the graph.
+type weldPlan struct {
+	g  *Graph
+	to func(id int64) int64
+	// ids holds the sorted IDs of the merged nodes.
+	ids []int64
+
+	// next, prev and face hold the changed links and faces of halfedges.
+	next, prev map[Halfedge]Halfedge
+	face       map[Halfedge]Face
+	// links holds the changed links in the order of the changes.
+	links [][2]Halfedge
+	// stitched holds the removed edges and removed holds their halfedges.
+	stitched []Edge
+	removed  map[Halfedge]bool
+	// roots holds the IDs of the nodes around the changes that remain after
+	// welding and hedges holds the halfedges from them.
+	roots  []int64
+	hedges map[int64][]Halfedge
+}
+
+func (p *weldPlan) nextOf(h Halfedge) Halfedge {
+	if n, ok := p.next[h]; ok {
+		return n
+	}
+	return h.Next()
+}
+
+func (p *weldPlan) prevOf(h Halfedge) Halfedge {
+	if n, ok := p.prev[h]; ok {
+		return n
+	}
+	return h.Prev()
+}
+
+func (p *weldPlan) faceOf(h Halfedge) Face {
+	if f, ok := p.face[h]; ok {
+		return f
+	}
+	return h.Face()
+}
+
+func (p *weldPlan) link(in, out Halfedge) {
+	p.next[in] = out
+	p.prev[out] = in
+	p.links = append(p.links, [2]Halfedge{in, out})
+}
+
+// stitch records the stitching of the edge of the halfedge b without a face
+// to the edge of the halfedge h with a face. The edge of h is removed and b
+// takes the place of h around its face. The boundary loops of b and of the
+// twin of h are joined where the removed twin was.
+func (p *weldPlan) stitch(b, h Halfedge) {
+	t := h.Twin()
+	if n := p.nextOf(b); n != t {
+		p.link(p.prevOf(t), n)
+	}
+	if n := p.nextOf(t); n != b {
+		p.link(p.prevOf(b), n)
+	}
+	p.link(p.prevOf(h), b)
+	p.link(b, p.nextOf(h))
+	p.face[b] = p.faceOf(h)
+	p.stitched = append(p.stitched, h.Edge())
+	p.removed[h] = true
+	p.removed[t] = true
+}
+
+// join records the joining of the halfedges from each node that remains after
+// welding into a single rotation. The halfedges from merged nodes and around
+// stitched edges can form several rotations, which are spliced together at
+// their gaps between halfedges without a face. If one of several rotations
+// does not have a gap, an error is returned.
+func (p *weldPlan) join() error {
+	var nodes []int64
+	for _, id := range p.ids {
+		nodes = append(nodes, id, p.to(id))
+	}
+	for _, e := range p.stitched {
+		nodes = append(nodes, e.From().ID(), e.To().ID())
+	}
+	p.hedges = make(map[int64][]Halfedge)
+	visited := make(map[int64]bool)
+	for _, id := range nodes {
+		if visited[id] {
+			continue
+		}
+		visited[id] = true
+		r := p.to(id)
+		for _, h := range p.g.HalfedgesFrom(id) {
+			if !p.removed[h] {
+				p.hedges[r] = append(p.hedges[r], h)
+			}
+		}
+	}
+	for r := range p.hedges {
+		p.roots = append(p.roots, r)
+	}
+	sort.Slice(p.roots, func(i, j int) bool { return p.roots[i] < p.roots[j] })
+	for _, r := range p.roots {
+		var (
+			gaps   []Halfedge
+			closed bool
+			seen   = make(map[Halfedge]bool)
+		)
+		for _, h := range p.hedges[r] {
+			if seen[h] {
+				continue
+			}
+			var gap Halfedge
+			for iter := h; !seen[iter]; iter = p.nextOf(iter.Twin()) {
+				seen[iter] = true
+				if gap == nil && p.faceOf(iter) == nil {
+					gap = iter
+				}
+			}
+			closed = closed || gap == nil
+			gaps = append(gaps, gap)
+		}
+		if len(gaps) < 2 {
+			continue
+		}
+		if closed {
+			return fmt.Errorf("dcel: cannot weld at node %d, a closed fan of faces would touch other faces", r)
+		}
+		hu := gaps[0]
+		for _, hv := range gaps[1:] {
+			inU, inV := p.prevOf(hu), p.prevOf(hv)
+			p.link(inU, hv)
+			p.link(inV, hu)
+		}
+	}
+	return nil
+}
+
+// apply applies the plan to the graph. The stitched edges and the merged nodes
+// are reported as removed before they are disconnected. Then the changed links
+// are reported, followed by the links of the halfedges that moved to another
+// node to their incoming neighbors.
+func (p *weldPlan) apply() {
+	g := p.g
+	for _, e := range p.stitched {
+		for _, o := range g.observers {
+			o.EdgeRemoved(e)
+		}
+	}
+	for _, id := range p.ids {
+		for _, o := range g.observers {
+			o.NodeRemoved(g.nodes[id])
+		}
+	}
+
+	for _, l := range p.links {
+		// Links of removed halfedges are applied as well, they are
+		// cleared below.
+		l[0].SetNext(l[1])
+		l[1].SetPrev(l[0])
+	}
+	for h, f := range p.face {
+		h.SetFace(f)
+		f.SetHalfedge(h)
+	}
+	for _, e := range p.stitched {
+		h1, h2 := e.Halfedges()
+		g.props.removeEdge(e.ID(), h1, h2)
+		reset(h1)
+		reset(h2)
+		g.deleteEdge(e.ID())
+	}
+	var moved []Halfedge
+	for _, r := range p.roots {
+		u := g.nodes[r]
+		u.SetHalfedge(nil)
+		for _, h := range p.hedges[r] {
+			if h.From() != u {
+				h.SetFrom(u)
+				moved = append(moved, h)
+			}
+			// Prefer a halfedge without a face as the halfedge of u.
+			if u.Halfedge() == nil || u.Halfedge().Face() != nil {
+				u.SetHalfedge(h)
+			}
+		}
+	}
+	for _, id := range p.ids {
+		g.nodes[id].SetHalfedge(nil)
+		g.deleteNode(id)
+	}
+
+	for _, l := range p.links {
+		if !p.removed[l[0]] && !p.removed[l[1]] {
+			g.reconnected(l[0], l[1])
+		}
+	}
+	for _, h := range moved {
+		g.reconnected(h.Prev(), h)
+	}
+}
+
+// freeHalfedge returns an outgoing halfedge from u without an adjacent face or
+// nil if there is none.
+func freeHalfedge(u Node) Halfedge {
+	start := u.Halfedge()
+	if start == nil {
+		return nil
+	}
+	for h := start; ; {
+		if h.Face() == nil {
+			return h
+		}
+		h = h.Twin().Next()
+		if h == start {
+			return nil
+		}
+	}
+}
+
+// faceHalfedge returns the halfedge of e with an adjacent face if e has
+// exactly one adjacent face, and nil otherwise.
+func faceHalfedge(e Edge) Halfedge {
+	h1, h2 := e.Halfedges()
+	switch {
+	case h1.Face() != nil && h2.Face() == nil:
+		return h1
+	case h1.Face() == nil && h2.Face() != nil:
+		return h2
+	}
+	return nil
+}
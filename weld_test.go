@@ -0,0 +1,182 @@
+package dcel
+
+import (
+	"math/rand/v2"
+	"reflect"
+	"testing"
+)
+
+// newSoup returns a graph with a separate triangle for each of the faces
+// given by indices of points. Each triangle has its own nodes.
+func newSoup(pts []Point, faces [][]int) *Graph {
+	g := New(PointBase{})
+	for _, f := range faces {
+		u := addPointNode(g, g.NewNodeID(), pts[f[0]])
+		v := addPointNode(g, g.NewNodeID(), pts[f[1]])
+		w := addPointNode(g, g.NewNodeID(), pts[f[2]])
+		if err := g.AddFace(g.NewFaceID(), u, v, w); err != nil {
+			panic(err)
+		}
+	}
+	return g
+}
+
+func TestWeldNodes(t *testing.T) {
+	// Eight triangles covering a square divided into four squares.
+	var pts []Point
+	for j := 0; j < 3; j++ {
+		for i := 0; i < 3; i++ {
+			pts = append(pts, Point{X: float64(i), Y: float64(j) + 1e-9*float64(i)})
+		}
+	}
+	g := newSoup(pts, [][]int{
+		{0, 1, 4}, {0, 4, 3}, {1, 2, 5}, {1, 5, 4},
+		{3, 4, 7}, {3, 7, 6}, {4, 5, 8}, {4, 8, 7},
+	})
+	label, err := AddFaceProperty[int](g, "label")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range g.Faces() {
+		label.Set(f, int(f.ID()))
+	}
+
+	merged, err := g.WeldNodes(1e-6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(merged) != 24-9 || merged[4] != 2 || merged[23] != 14 {
+		t.Errorf("dcel: wrong merged nodes: %v", merged)
+	}
+	checkLinks(t, g)
+	topo := g.Topology()
+	want := Component{
+		Nodes: 9, Edges: 16, Faces: 8,
+		BoundaryLoops: 1, EulerCharacteristic: 1,
+		Manifold: true,
+	}
+	if len(topo.Components) != 1 || !reflect.DeepEqual(topo.Components[0], want) {
+		t.Errorf("dcel: wrong topology after welding: %+v", topo.Components)
+	}
+	for _, f := range g.Faces() {
+		if label.Get(f) != int(f.ID()) || faceArea(g, f) <= 0 {
+			t.Errorf("dcel: face %d not kept", f.ID())
+		}
+	}
+	if merged, err := g.WeldNodes(1e-6); err != nil || len(merged) != 0 {
+		t.Errorf("dcel: unexpected welding of welded graph: %v, %v", merged, err)
+	}
+}
+
+func TestWeldNodesClosed(t *testing.T) {
+	var corners []Point
+	for _, z := range []float64{-1, 1} {
+		for _, y := range []float64{-1, 1} {
+			for _, x := range []float64{-1, 1} {
+				corners = append(corners, Point{X: x, Y: y, Z: z})
+			}
+		}
+	}
+	g := newSoup(corners, [][]int{
+		{0, 2, 3}, {0, 3, 1}, {4, 5, 7}, {4, 7, 6}, {0, 1, 5}, {0, 5, 4},
+		{2, 6, 7}, {2, 7, 3}, {0, 4, 6}, {0, 6, 2}, {1, 3, 7}, {1, 7, 5},
+	})
+	if _, err := g.WeldNodes(0); err != nil {
+		t.Fatal(err)
+	}
+	checkLinks(t, g)
+	checkClosed(t, g, 12, 3)
+	if topo := g.Topology(); topo.Nodes != 8 || topo.EulerCharacteristic != 2 {
+		t.Errorf("dcel: wrong topology of welded cube: %+v", topo)
+	}
+}
+
+func TestWeldNodesError(t *testing.T) {
+	// A triangle with a short edge.
+	g := newSoup([]Point{{}, {X: 1e-9}, {Y: 1}}, [][]int{{0, 1, 2}})
+	if _, err := g.WeldNodes(1e-6); err == nil {
+		t.Error("dcel: expected error for nodes joined by an edge")
+	}
+	if g.Nodes().Len() != 3 {
+		t.Error("dcel: graph modified")
+	}
+
+	// Two triangles with the same orientation of the shared edge.
+	g = newSoup([]Point{{}, {X: 1}, {Y: 1}, {Y: -1}}, [][]int{{0, 1, 2}, {0, 3, 1}, {0, 1, 3}})
+	g.RemoveFace(g.Face(1))
+	if _, err := g.WeldNodes(0); err == nil {
+		t.Error("dcel: expected error for edges with faces on the same side")
+	}
+
+	// Two copies of a triangle with opposite orientations and a triangle
+	// touching them at node 2. The stitched pair of triangles forms a closed
+	// fan around the merged node 2 that would touch the other triangle.
+	g = newSoup([]Point{{}, {X: 1}, {Y: 1}, {X: 1, Y: 1}, {X: 2, Y: 1}}, [][]int{{1, 0, 2}, {2, 0, 1}, {3, 2, 4}})
+	r := &recorder{g: g}
+	g.AddObserver(r)
+	if _, err := g.WeldNodes(0); err == nil {
+		t.Error("dcel: expected error for a closed fan touching other faces")
+	}
+	if g.Nodes().Len() != 9 || g.Edges().Len() != 9 || len(g.Faces()) != 3 || len(r.events) != 0 {
+		t.Error("dcel: graph modified")
+	}
+	checkLinks(t, g)
+
+	if _, err := New(nil).WeldNodes(0); err != nil {
+		t.Errorf("dcel: unexpected error for empty graph: %v", err)
+	}
+	g = New(nil)
+	g.AddNode(0)
+	if _, err := g.WeldNodes(0); err == nil {
+		t.Error("dcel: expected error for nodes without a position")
+	}
+}
+
+func TestWeldNodesObserver(t *testing.T) {
+	// A soup of the triangles of a grid in random order, so that the fans
+	// around the nodes are welded in various orders.
+	var (
+		pts   []Point
+		faces [][]int
+	)
+	const n = 5
+	for j := 0; j <= n; j++ {
+		for i := 0; i <= n; i++ {
+			pts = append(pts, Point{X: float64(i), Y: float64(j)})
+		}
+	}
+	for j := 0; j < n; j++ {
+		for i := 0; i < n; i++ {
+			a := j*(n+1) + i
+			faces = append(faces, []int{a, a + 1, a + n + 2}, []int{a, a + n + 2, a + n + 1})
+		}
+	}
+	rnd := rand.New(rand.NewPCG(1, 5))
+	for k := 0; k < 10; k++ {
+		rnd.Shuffle(len(faces), func(i, j int) { faces[i], faces[j] = faces[j], faces[i] })
+		g := newSoup(pts, faces)
+		r := &recorder{g: g}
+		rp := &replayer{next: make(map[Halfedge]Halfedge)}
+		for _, id := range g.edgeIDs() {
+			rp.EdgeAdded(g.edges[id])
+		}
+		g.AddObserver(r)
+		g.AddObserver(rp)
+
+		if _, err := g.WeldNodes(0); err != nil {
+			t.Fatal(err)
+		}
+		checkLinks(t, g)
+		rp.check(t, g)
+		if g.Nodes().Len() != len(pts) || len(g.BoundaryLoops()) != 1 {
+			t.Errorf("dcel: wrong welded grid: %d nodes, %d boundary loops", g.Nodes().Len(), len(g.BoundaryLoops()))
+		}
+		// Faces are neither removed nor added back.
+		for _, ev := range r.events {
+			switch ev[:2] {
+			case "+f", "-f", "+e", "+n":
+				t.Fatalf("dcel: unexpected event %s", ev)
+			}
+		}
+	}
+}